@@ -0,0 +1,311 @@
+// Command analyze runs the webpage analyzer from a terminal or CI script.
+//
+// Usage:
+//
+//	analyze [flags] <url | file.html | -> ...
+//
+// Examples:
+//
+//	analyze https://example.com
+//	analyze -format json -max-broken 0 https://example.com https://example.org
+//	analyze -base-url https://staging.example.com build/index.html
+//
+// Exit codes: 0 = all good, 1 = a threshold was crossed, 2 = usage or analysis error.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v2"
+
+	"webpage-analyzer/internal/analyzer"
+)
+
+// Exit codes
+const (
+	exitOK        = 0
+	exitThreshold = 1
+	exitError     = 2
+)
+
+// report is the outcome for one input (URL or file)
+type report struct {
+	Source   string                   `json:"source"`
+	Result   *analyzer.AnalysisResult `json:"result,omitempty"`
+	Error    string                   `json:"error,omitempty"`
+	Failures []string                 `json:"failures,omitempty"` // Thresholds that were crossed
+}
+
+// thresholds decide when the CLI exits non-zero
+type thresholds struct {
+	maxBroken    int  // Fail if more broken links than this (-1 = don't check)
+	requireTitle bool // Fail if <title> is missing or empty
+	requireH1    bool // Fail if there's no <h1>
+}
+
+// check returns a human-readable line for each threshold the result crosses
+func (t thresholds) check(res *analyzer.AnalysisResult) []string {
+	var failures []string
+	if t.maxBroken >= 0 && res.Links.Broken > t.maxBroken {
+		failures = append(failures, fmt.Sprintf("%d broken link(s), max %d", res.Links.Broken, t.maxBroken))
+	}
+	if t.requireTitle && strings.TrimSpace(res.Title) == "" {
+		failures = append(failures, "missing title")
+	}
+	if t.requireH1 && res.Headings["h1"] == 0 {
+		failures = append(failures, "no <h1> heading")
+	}
+	return failures
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run is main without os.Exit, so the flow is easy to follow (and to test)
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "output format: text, json or yaml")
+	baseURL := fs.String("base-url", "", "base URL used to resolve relative links in local files")
+	noLinkCheck := fs.Bool("no-check-links", false, "don't request links in local files (they are still resolved and classified)")
+	timeout := fs.Duration("timeout", 2*time.Minute, "overall timeout per input (URL, file or stdin)")
+	userAgent := fs.String("user-agent", "", "User-Agent sent when fetching pages (default: the analyzer's own)")
+	ignoreRobots := fs.Bool("ignore-robots", false, "don't check robots.txt before fetching pages and links (e.g. for your own staging site)")
+	allow := fs.String("allow", "", "comma-separated CIDRs that may be fetched even though they are private (e.g. 127.0.0.1 for a local server)")
+	var th thresholds
+	fs.IntVar(&th.maxBroken, "max-broken", -1, "fail if there are more broken links than this (0 = any broken link fails, -1 = off)")
+	fs.BoolVar(&th.requireTitle, "require-title", false, "fail if the page has no <title>")
+	fs.BoolVar(&th.requireH1, "require-h1", false, "fail if the page has no <h1>")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: analyze [flags] <url | file.html | -> ...")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitError
+	}
+	// stdin can only be read once: a second "-" would analyze an empty page
+	if first := slices.Index(fs.Args(), "-"); first >= 0 && slices.Contains(fs.Args()[first+1:], "-") {
+		fmt.Fprintln(stderr, `"-" (stdin) can only be given once`)
+		return exitError
+	}
+	if *format != "text" && *format != "json" && *format != "yaml" {
+		fmt.Fprintf(stderr, "unknown -format %q (want text, json or yaml)\n", *format)
+		return exitError
+	}
+
+	// Same SSRF guard as the server, plus whatever the caller explicitly allows
+	if *allow != "" {
+		policy, err := analyzer.NewAddressPolicy(strings.Split(*allow, ","), analyzer.DefaultDeniedCIDRs)
+		if err != nil {
+			fmt.Fprintf(stderr, "invalid -allow: %v\n", err)
+			return exitError
+		}
+		analyzer.TargetPolicy = policy
+	}
+
+	analyzer.RespectRobots = !*ignoreRobots
+	analyzer.PageFetcher.UserAgent = *userAgent
+
+	// === Analyze every input ===
+	reports := make([]report, 0, fs.NArg())
+	for _, src := range fs.Args() {
+		rep := report{Source: src}
+		res, err := analyzeSource(src, *baseURL, !*noLinkCheck, *timeout, stdin)
+		if err != nil {
+			rep.Error = err.Error()
+		} else {
+			rep.Result = res
+			rep.Failures = th.check(res)
+		}
+		reports = append(reports, rep)
+	}
+
+	// === Print ===
+	var err error
+	switch *format {
+	case "json":
+		err = writeJSON(stdout, reports)
+	case "yaml":
+		err = writeYAML(stdout, reports)
+	default:
+		writeText(stdout, reports)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "output error: %v\n", err)
+		return exitError
+	}
+
+	return exitCode(reports)
+}
+
+// analyzeSource handles one argument:
+// - http(s)://... → fetch + analyze (same pipeline as the server)
+// - "-"           → read HTML from stdin
+// - anything else → read a local HTML file
+// Either way, timeout bounds the whole analysis, link checks included.
+func analyzeSource(src, baseURL string, checkLinks bool, timeout time.Duration, stdin io.Reader) (*analyzer.AnalysisResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		return analyzer.AnalyzeURL(ctx, src, analyzer.Options{})
+	}
+
+	var body io.Reader = stdin
+	if src != "-" {
+		f, err := os.Open(src)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		body = f
+	}
+	return analyzer.AnalyzeHTML(ctx, body, baseURL, checkLinks)
+}
+
+// exitCode: any analysis error → 2, otherwise any threshold crossed → 1
+func exitCode(reports []report) int {
+	code := exitOK
+	for _, rep := range reports {
+		if rep.Error != "" {
+			return exitError
+		}
+		if len(rep.Failures) > 0 {
+			code = exitThreshold
+		}
+	}
+	return code
+}
+
+// writeJSON prints all reports as one indented JSON array
+func writeJSON(w io.Writer, reports []report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(reports)
+}
+
+// writeYAML prints the same structure as writeJSON, in YAML.
+// Going through JSON first keeps the field names identical (html_version, ...).
+func writeYAML(w io.Writer, reports []report) error {
+	data, err := json.Marshal(reports)
+	if err != nil {
+		return err
+	}
+	var generic any
+	if err := yaml.Unmarshal(data, &generic); err != nil { // JSON is valid YAML
+		return err
+	}
+	out, err := yaml.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// writeText prints a short human-readable summary per input
+func writeText(w io.Writer, reports []report) {
+	for i, rep := range reports {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "== %s ==\n", rep.Source)
+		if rep.Error != "" {
+			fmt.Fprintf(w, "ERROR: %s\n", rep.Error)
+			continue
+		}
+
+		res := rep.Result
+		fmt.Fprintf(w, "HTML version: %s (%s mode)\n", res.HTMLVersion, res.Doctype.Mode)
+		if f := res.Fetch; f != nil {
+			fmt.Fprintf(w, "Fetch:        HTTP %d, %d bytes in %d ms (dns %d, connect %d, tls %d, ttfb %d, download %d)\n",
+				f.StatusCode, f.BodyBytes, f.Timings.TotalMS, f.Timings.DNSMS, f.Timings.ConnectMS,
+				f.Timings.TLSMS, f.Timings.TTFBMS, f.Timings.DownloadMS)
+		}
+		if sec := res.Security; sec != nil {
+			fmt.Fprintf(w, "Security:     grade %s (%d/100)\n", sec.Grade, sec.Score)
+		}
+		fmt.Fprintf(w, "Title:        %s\n", res.Title)
+		fmt.Fprintf(w, "Headings:     %s\n", formatHeadings(res.Headings))
+		fmt.Fprintf(w, "Login form:   %s\n", yesNo(res.HasLoginForm))
+		fmt.Fprintf(w, "Links:        %d internal, %d external, %d unclassified | %d ok, %d broken, %d unchecked\n",
+			res.Links.Internal, res.Links.External, res.Links.Unclassified,
+			res.Links.OK, res.Links.Broken, res.Links.Unchecked)
+
+		for _, l := range res.Links.Items {
+			if l.Health == analyzer.LinkBroken {
+				fmt.Fprintf(w, "  broken: %-40s %s\n", l.URL, l.Error)
+			}
+		}
+
+		for _, issue := range res.SEO.Issues {
+			fmt.Fprintf(w, "  seo: %s\n", issue.Message)
+		}
+
+		for _, f := range res.Forms {
+			fmt.Fprintf(w, "  form: %s (%d%%) at %s\n", f.Purpose, f.ConfidencePercent(), f.Path)
+		}
+
+		for _, issue := range res.Accessibility.Issues {
+			fmt.Fprintf(w, "  a11y: %s %s at %s\n", issue.Severity, issue.Message, issue.Path)
+		}
+
+		for _, m := range res.MixedContent.Items {
+			credentials := ""
+			if m.Credentials {
+				credentials = " (sends credentials!)"
+			}
+			fmt.Fprintf(w, "  mixed: %s %s %s at %s%s\n", m.Type, m.Tag, m.URL, m.Path, credentials)
+		}
+
+		if sec := res.Security; sec != nil {
+			for _, c := range sec.Checks {
+				if c.Status == analyzer.CheckFail || c.Status == analyzer.CheckWarn {
+					fmt.Fprintf(w, "  security: %s %s: %s\n", c.Status, c.Name, c.Message)
+				}
+			}
+		}
+
+		for _, f := range rep.Failures {
+			fmt.Fprintf(w, "FAIL: %s\n", f)
+		}
+	}
+}
+
+// formatHeadings → "h1=1 h2=3" (sorted), or "none"
+func formatHeadings(h map[string]int) string {
+	if len(h) == 0 {
+		return "none"
+	}
+	levels := make([]string, 0, len(h))
+	for level := range h {
+		levels = append(levels, level)
+	}
+	sort.Strings(levels)
+	parts := make([]string, len(levels))
+	for i, level := range levels {
+		parts[i] = fmt.Sprintf("%s=%d", level, h[level])
+	}
+	return strings.Join(parts, " ")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -1,8 +1,13 @@
 package main
 
 import (
+	"fmt"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/didip/tollbooth/v7"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
@@ -24,6 +29,59 @@ func main() {
 		port = "8080"
 	}
 
+	// === Cache backend ===
+	analyzer.ResultCache = newCache()
+
+	// CACHE_TTL: how long results stay cached, e.g. "30m" or "2h".
+	// A bare number is read as hours (the old behaviour).
+	if ttl := os.Getenv("CACHE_TTL"); ttl != "" {
+		d, err := parseTTL(ttl)
+		if err != nil {
+			logger.WithError(err).Fatal("Invalid CACHE_TTL")
+		}
+		analyzer.CacheTTL = d
+	}
+
+	// === SSRF guard ===
+	// SSRF_DENY_CIDRS replaces the default deny list; SSRF_ALLOW_CIDRS punches holes in it.
+	// Both are comma-separated, e.g. SSRF_ALLOW_CIDRS=10.0.5.0/24,192.168.1.10
+	deny := analyzer.DefaultDeniedCIDRs
+	if v := os.Getenv("SSRF_DENY_CIDRS"); v != "" {
+		deny = strings.Split(v, ",")
+	}
+	policy, err := analyzer.NewAddressPolicy(strings.Split(os.Getenv("SSRF_ALLOW_CIDRS"), ","), deny)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid SSRF_ALLOW_CIDRS / SSRF_DENY_CIDRS")
+	}
+	analyzer.TargetPolicy = policy
+
+	// === Page fetcher ===
+	analyzer.PageFetcher.Timeout = envDuration("FETCH_TIMEOUT", analyzer.PageFetcher.Timeout)
+	analyzer.PageFetcher.MaxBodySize = int64(envInt("FETCH_MAX_BODY_BYTES", int(analyzer.PageFetcher.MaxBodySize)))
+	analyzer.PageFetcher.MaxRedirects = envInt("FETCH_MAX_REDIRECTS", analyzer.PageFetcher.MaxRedirects)
+	if v := os.Getenv("FETCH_USER_AGENT"); v != "" {
+		analyzer.PageFetcher.UserAgent = v
+	}
+	if v, ok := os.LookupEnv("FETCH_ACCEPT_LANGUAGE"); ok {
+		analyzer.PageFetcher.AcceptLanguage = v // may be set to "" to not send the header
+	}
+	switch policy := analyzer.RedirectPolicy(os.Getenv("FETCH_REDIRECTS")); policy {
+	case "":
+	case analyzer.RedirectFollow, analyzer.RedirectSameHost, analyzer.RedirectNone:
+		analyzer.PageFetcher.Redirects = policy
+	default:
+		logger.Fatalf("Invalid FETCH_REDIRECTS %q (want follow, same-host or none)", policy)
+	}
+
+	// === Link checker ===
+	analyzer.MaxLinkRedirects = envInt("LINK_MAX_REDIRECTS", analyzer.MaxLinkRedirects)
+
+	// === Politeness: robots.txt + per-host limits (pages and link checks) ===
+	analyzer.RespectRobots = envBool("ROBOTS_ENABLED", analyzer.RespectRobots)
+	analyzer.HostConcurrency = envInt("HOST_CONCURRENCY", analyzer.HostConcurrency)
+	analyzer.HostDelay = envDuration("HOST_DELAY", analyzer.HostDelay)
+	analyzer.MaxCrawlDelay = envDuration("MAX_CRAWL_DELAY", analyzer.MaxCrawlDelay)
+
 	// === Template & Metrics Init ===
 	analyzer.Tmpl = analyzer.LoadTemplate()
 	analyzer.InitMetrics() // ← NEW: Register Prometheus metrics
@@ -38,6 +96,57 @@ func main() {
 		tollbooth.LimitFuncHandler(limiter, analyzer.AnalyzeHandler(logger)),
 	)
 
+	// === Uploaded / pasted HTML (no fetch; link checks optional) ===
+	http.Handle("/analyze/html",
+		tollbooth.LimitFuncHandler(limiter, analyzer.AnalyzeHTMLHandler(logger)),
+	)
+	http.Handle("/api/v1/analyze/html",
+		tollbooth.LimitFuncHandler(limiter, analyzer.APIAnalyzeHTMLHandler(logger)),
+	)
+
+	// === JSON API (v1) – same pipeline, JSON in / JSON out ===
+	http.Handle("/api/v1/analyze",
+		tollbooth.LimitFuncHandler(limiter, analyzer.APIAnalyzeHandler(logger)),
+	)
+
+	// === Batch analysis: many URLs, one request (counts once against the limiter) ===
+	analyzer.MaxBatchURLs = envInt("BATCH_MAX_URLS", analyzer.MaxBatchURLs)
+	analyzer.BatchConcurrency = envInt("BATCH_CONCURRENCY", analyzer.BatchConcurrency)
+	http.Handle("/api/v1/batch",
+		tollbooth.LimitFuncHandler(limiter, analyzer.BatchHandler(logger)),
+	)
+
+	// === Site crawl: follow internal links breadth-first from a seed URL ===
+	analyzer.CrawlMaxDepth = envInt("CRAWL_MAX_DEPTH", analyzer.CrawlMaxDepth)
+	analyzer.CrawlMaxPages = envInt("CRAWL_MAX_PAGES", analyzer.CrawlMaxPages)
+	analyzer.CrawlConcurrency = envInt("CRAWL_CONCURRENCY", analyzer.CrawlConcurrency)
+	http.Handle("/api/v1/crawl",
+		tollbooth.LimitFuncHandler(limiter, analyzer.CrawlHandler(logger)),
+	)
+
+	// === Sitemap: find sitemap.xml, analyze every listed page ===
+	analyzer.SitemapMaxURLs = envInt("SITEMAP_MAX_URLS", analyzer.SitemapMaxURLs)
+	analyzer.SitemapConcurrency = envInt("SITEMAP_CONCURRENCY", analyzer.SitemapConcurrency)
+	http.Handle("/api/v1/sitemap",
+		tollbooth.LimitFuncHandler(limiter, analyzer.SitemapHandler(logger)),
+	)
+
+	// === Live progress stream (SSE, or JSON lines with ?format=jsonl) ===
+	http.Handle("/api/v1/analyze/stream",
+		tollbooth.LimitFuncHandler(limiter, analyzer.StreamAnalyzeHandler(logger)),
+	)
+
+	// === Async jobs: submit → 202 + job ID, then poll /api/v1/jobs/{id} ===
+	jobs := analyzer.NewJobManager(logger,
+		envInt("JOB_WORKERS", 4),
+		envInt("JOB_QUEUE_SIZE", 100),
+		envDuration("JOB_RETENTION", time.Hour),
+	)
+	http.Handle("/api/v1/jobs",
+		tollbooth.LimitFuncHandler(limiter, analyzer.JobSubmitHandler(logger, jobs)),
+	)
+	http.Handle("/api/v1/jobs/{id}", analyzer.JobStatusHandler(jobs)) // polling isn't rate limited
+
 	// === Prometheus Metrics Endpoint ===
 	http.Handle("/metrics", promhttp.Handler())
 
@@ -47,4 +156,94 @@ func main() {
 	if err := http.ListenAndServe(":"+port, nil); err != nil {
 		logger.WithError(err).Fatal("Server failed")
 	}
-}
\ No newline at end of file
+}
+
+// newCache picks the cache backend from CACHE_BACKEND:
+// - "redis":  shared Redis at REDIS_ADDR (default when REDIS_ADDR is set)
+// - "memory": in-process LRU, size from CACHE_MAX_ENTRIES (default otherwise)
+// - "none":   caching disabled
+func newCache() analyzer.Cache {
+	backend := os.Getenv("CACHE_BACKEND")
+	if backend == "" {
+		backend = "memory"
+		if os.Getenv("REDIS_ADDR") != "" {
+			backend = "redis"
+		}
+	}
+
+	switch backend {
+	case "redis":
+		addr := os.Getenv("REDIS_ADDR")
+		if addr == "" {
+			addr = "localhost:6379" // fallback if env var not set
+		}
+		logger.Infof("Cache: redis at %s", addr)
+		return analyzer.NewRedisCache(addr)
+	case "memory":
+		maxEntries := envInt("CACHE_MAX_ENTRIES", 1000)
+		logger.Infof("Cache: in-memory (max %d entries)", maxEntries)
+		return analyzer.NewMemoryCache(maxEntries)
+	case "none":
+		logger.Info("Cache: disabled")
+		return analyzer.NoopCache{}
+	default:
+		logger.Fatalf("Unknown CACHE_BACKEND %q (want redis, memory or none)", backend)
+		return nil
+	}
+}
+
+// envInt reads an integer env var, falling back to def when unset
+func envInt(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		logger.WithError(err).Fatalf("Invalid %s", name)
+	}
+	return n
+}
+
+// envBool reads a boolean env var ("true", "false", "1", "0"), falling back to def when unset
+func envBool(name string, def bool) bool {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		logger.WithError(err).Fatalf("Invalid %s", name)
+	}
+	return b
+}
+
+// envDuration reads a Go duration env var ("90s", "1h"), falling back to def when unset
+func envDuration(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		logger.WithError(err).Fatalf("Invalid %s", name)
+	}
+	return d
+}
+
+// parseTTL accepts a Go duration ("90m") or a plain number of hours ("2").
+// The TTL must be positive: Redis reads 0 as "never expire" while the
+// in-memory cache would expire entries at once, so 0 means nothing useful.
+func parseTTL(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if hours, atoiErr := strconv.Atoi(s); atoiErr == nil {
+		d, err = time.Duration(hours)*time.Hour, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("TTL must be positive, got %s", s)
+	}
+	return d, nil
+}
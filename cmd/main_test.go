@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseTTL(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"90m", 90 * time.Minute, false},
+		{"2", 2 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"0", 0, true},
+		{"0s", 0, true},
+		{"-1", 0, true},
+		{"-5m", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseTTL(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseTTL(%q) = %v, %v; want %v (error: %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNewCache(t *testing.T) {
+	tests := []struct {
+		name, backend, redisAddr string
+		want                     string // %T of the cache
+	}{
+		{"default is memory", "", "", "*analyzer.MemoryCache"},
+		{"REDIS_ADDR implies redis", "", "redis:6379", "*analyzer.RedisCache"},
+		{"explicit memory wins over REDIS_ADDR", "memory", "redis:6379", "*analyzer.MemoryCache"},
+		{"explicit redis", "redis", "", "*analyzer.RedisCache"},
+		{"none", "none", "", "analyzer.NoopCache"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CACHE_BACKEND", tt.backend)
+			t.Setenv("REDIS_ADDR", tt.redisAddr)
+
+			got := newCache() // Redis connects lazily: nothing is dialed here
+			if typ := fmt.Sprintf("%T", got); typ != tt.want {
+				t.Errorf("newCache() = %s; want %s", typ, tt.want)
+			}
+		})
+	}
+}
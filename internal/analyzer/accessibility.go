@@ -0,0 +1,281 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Accessibility is a WCAG-oriented audit of the page's markup.
+// It only catches what can be seen in the HTML (no colours, no scripts).
+type Accessibility struct {
+	Errors   int                  `json:"errors"`   // Issues with severity "error"
+	Warnings int                  `json:"warnings"` // Issues with severity "warning"
+	Issues   []AccessibilityIssue `json:"issues"`   // In document order
+}
+
+// Values of AccessibilityIssue.Severity
+const (
+	SeverityError   = "error"   // Blocks some users (e.g. screen readers can't name the element)
+	SeverityWarning = "warning" // Makes the page harder to use
+)
+
+// Values of AccessibilityIssue.Rule
+const (
+	RuleImageAlt     = "image-alt"     // <img> without an alt attribute
+	RuleLabel        = "label"         // Form control without a label
+	RuleHeadingOrder = "heading-order" // Heading level skipped, e.g. h1 → h3
+	RuleHTMLLang     = "html-lang"     // <html> without lang
+	RuleLinkName     = "link-name"     // Link with no text
+	RuleButtonName   = "button-name"   // Button with no text
+	RuleDuplicateID  = "duplicate-id"  // Same id on several elements
+	RuleTabindex     = "tabindex"      // tabindex > 0 (breaks the natural tab order)
+)
+
+// AccessibilityIssue is one finding on one element
+type AccessibilityIssue struct {
+	Rule     string `json:"rule"`     // image-alt / label / heading-order / ...
+	Severity string `json:"severity"` // error / warning
+	Path     string `json:"path"`     // CSS-like path to the element, e.g. "form#login > input:nth-of-type(2)"
+	Message  string `json:"message"`  // Human-readable, for the results page
+
+	order int // Position in the document, so late-resolved issues (labels) sort correctly
+}
+
+// a11yCollector is fed every element by the DOM traversal in AnalyzePage;
+// build() then resolves what needs the whole page (label for="...") and sorts
+type a11yCollector struct {
+	issues    []AccessibilityIssue
+	seen      int                   // Elements visited so far → AccessibilityIssue.order
+	lastLevel int                   // Level of the previous heading (0 = none yet)
+	ids       map[string]*html.Node // id → its first element
+	labelFor  map[string]bool       // ids named by <label for="...">
+	controls  []a11yControl         // Form controls that still need a label check
+}
+
+// a11yControl is a form control without an obvious label; it's fine if a
+// <label for="its id"> shows up anywhere in the page
+type a11yControl struct {
+	id    string
+	node  *html.Node
+	order int
+}
+
+func newA11yCollector() *a11yCollector {
+	return &a11yCollector{ids: make(map[string]*html.Node), labelFor: make(map[string]bool)}
+}
+
+// add records an issue at the current element n. The path is only built
+// here: cssPath scans siblings, too slow to run for every element of a big table.
+func (c *a11yCollector) add(n *html.Node, rule, severity, message string) {
+	c.issues = append(c.issues, AccessibilityIssue{Rule: rule, Severity: severity, Path: cssPath(n), Message: message, order: c.seen})
+}
+
+// element checks one element (called for every element, in document order)
+func (c *a11yCollector) element(n *html.Node) {
+	c.seen++
+	tag := strings.ToLower(n.Data)
+
+	// === Attributes any element can have ===
+	if id := attrValue(n, "id"); id != "" {
+		if first, ok := c.ids[id]; ok {
+			c.add(n, RuleDuplicateID, SeverityWarning, fmt.Sprintf("id %q is already used by %s", id, cssPath(first)))
+		} else {
+			c.ids[id] = n
+		}
+	}
+	if tabindex, err := strconv.Atoi(strings.TrimSpace(attrValue(n, "tabindex"))); err == nil && tabindex > 0 {
+		c.add(n, RuleTabindex, SeverityWarning, fmt.Sprintf("tabindex=%d changes the tab order; use 0 or -1", tabindex))
+	}
+
+	// === Tag-specific checks ===
+	switch tag {
+	case "html":
+		if strings.TrimSpace(attrValue(n, "lang")) == "" {
+			c.add(n, RuleHTMLLang, SeverityError, "<html> has no lang attribute")
+		}
+
+	case "img":
+		// alt="" is fine: it marks a decorative image
+		if !hasAttr(n, "alt") {
+			c.add(n, RuleImageAlt, SeverityError, "image has no alt attribute")
+		}
+
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level := int(tag[1] - '0')
+		if c.lastLevel > 0 && level > c.lastLevel+1 {
+			c.add(n, RuleHeadingOrder, SeverityWarning, fmt.Sprintf("heading jumps from h%d to h%d", c.lastLevel, level))
+		}
+		c.lastLevel = level
+
+	case "a":
+		if hasAttr(n, "href") && !hasAccessibleName(n) {
+			c.add(n, RuleLinkName, SeverityError, "link has no text")
+		}
+
+	case "button":
+		if !hasAccessibleName(n) {
+			c.add(n, RuleButtonName, SeverityError, "button has no text")
+		}
+
+	case "label":
+		if f := attrValue(n, "for"); f != "" {
+			c.labelFor[f] = true
+		}
+
+	case "input", "select", "textarea":
+		inputType := strings.ToLower(attrValue(n, "type"))
+		switch {
+		case tag == "input" && inputType == "hidden":
+			// Not shown, nothing to label
+		case tag == "input" && (inputType == "submit" || inputType == "reset" || inputType == "image"):
+			// Named by their value/alt (browsers show "Submit" by default)
+		case tag == "input" && inputType == "button":
+			if !hasAccessibleName(n) && strings.TrimSpace(attrValue(n, "value")) == "" {
+				c.add(n, RuleButtonName, SeverityError, "button has no text")
+			}
+		case hasARIAName(n) || insideLabel(n):
+			// Labelled
+		default:
+			// Maybe a <label for> later in the page: decide in build()
+			c.controls = append(c.controls, a11yControl{id: attrValue(n, "id"), node: n, order: c.seen})
+		}
+	}
+}
+
+// build resolves the label checks, sorts by document order and counts severities
+func (c *a11yCollector) build() Accessibility {
+	for _, ctl := range c.controls {
+		if ctl.id != "" && c.labelFor[ctl.id] {
+			continue
+		}
+		c.issues = append(c.issues, AccessibilityIssue{Rule: RuleLabel, Severity: SeverityError, Path: cssPath(ctl.node),
+			Message: "form control has no label", order: ctl.order})
+	}
+	sort.SliceStable(c.issues, func(i, j int) bool { return c.issues[i].order < c.issues[j].order })
+
+	a := Accessibility{Issues: c.issues}
+	if a.Issues == nil {
+		a.Issues = []AccessibilityIssue{}
+	}
+	for _, issue := range a.Issues {
+		if issue.Severity == SeverityError {
+			a.Errors++
+		} else {
+			a.Warnings++
+		}
+	}
+	return a
+}
+
+// hasAccessibleName is roughly "would a screen reader have something to announce?"
+// for a link or button: aria-label / aria-labelledby / title, its text, or the alt of an image inside
+func hasAccessibleName(n *html.Node) bool {
+	if hasARIAName(n) || textContent(n) != "" {
+		return true
+	}
+	found := false
+	var walk func(*html.Node)
+	walk = func(m *html.Node) {
+		if m.Type == html.ElementNode && m.Data == "img" && strings.TrimSpace(attrValue(m, "alt")) != "" {
+			found = true
+		}
+		for ch := m.FirstChild; ch != nil && !found; ch = ch.NextSibling {
+			walk(ch)
+		}
+	}
+	walk(n)
+	return found
+}
+
+// hasARIAName reports whether n is named by aria-label, aria-labelledby or title
+func hasARIAName(n *html.Node) bool {
+	for _, key := range []string{"aria-label", "aria-labelledby", "title"} {
+		if strings.TrimSpace(attrValue(n, key)) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// insideLabel reports whether n is wrapped in a <label> (an implicit label)
+func insideLabel(n *html.Node) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.Data == "label" {
+			return true
+		}
+	}
+	return false
+}
+
+// cssPath builds a short selector-like path to n, e.g. "body > div#main > p:nth-of-type(2) > img".
+// It stops at the nearest ancestor with an id, unless that id is used more
+// than once in the document (then it can't locate anything on its own),
+// and adds :nth-of-type only when a parent has several children with the same tag.
+func cssPath(n *html.Node) string {
+	root := n
+	for root.Parent != nil {
+		root = root.Parent
+	}
+
+	var parts []string
+	for m := n; m != nil && m.Type == html.ElementNode; m = m.Parent {
+		part := m.Data
+		if id := attrValue(m, "id"); id != "" {
+			part += "#" + id
+			if !idUsedTwice(root, id) {
+				parts = append(parts, part)
+				break
+			}
+		}
+		if index, count := typeIndex(m); count > 1 {
+			part += fmt.Sprintf(":nth-of-type(%d)", index)
+		}
+		parts = append(parts, part)
+	}
+
+	// Collected bottom-up → reverse
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, " > ")
+}
+
+// idUsedTwice reports whether at least two elements under root have this id
+func idUsedTwice(root *html.Node, id string) bool {
+	seen := 0
+	var walk func(*html.Node) bool
+	walk = func(n *html.Node) bool {
+		if n.Type == html.ElementNode && attrValue(n, "id") == id {
+			if seen++; seen > 1 {
+				return true
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if walk(c) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(root)
+}
+
+// typeIndex returns n's 1-based position among its siblings with the same tag, and how many there are
+func typeIndex(n *html.Node) (index, count int) {
+	if n.Parent == nil {
+		return 1, 1
+	}
+	for s := n.Parent.FirstChild; s != nil; s = s.NextSibling {
+		if s.Type == html.ElementNode && s.Data == n.Data {
+			count++
+			if s == n {
+				index = count
+			}
+		}
+	}
+	return index, count
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzePage_Accessibility(t *testing.T) {
+	page := `<!DOCTYPE html><html><head><title>T</title></head><body>
+<h1>Title</h1>
+<img src="logo.png">
+<img src="spacer.gif" alt="">
+<h3>Skipped h2</h3>
+<a href="/home"></a>
+<a href="/home"><img src="home.png" alt="Home"></a>
+<button aria-label="Close"></button>
+<button></button>
+<form id="login">
+  <label for="user">User</label><input id="user" name="user">
+  <label>Password <input type="password" name="pw"></label>
+  <input name="code">
+  <input type="hidden" name="token">
+  <input type="submit">
+</form>
+<div id="login"></div>
+<p tabindex="3">Focus me first</p>
+<p tabindex="0">Fine</p>
+</body></html>`
+
+	result, err := AnalyzePage(strings.NewReader(page), "")
+	if err != nil {
+		t.Fatalf("AnalyzePage: %v", err)
+	}
+	a := result.Accessibility
+
+	type finding struct{ rule, severity, path string }
+	var got []finding
+	for _, issue := range a.Issues {
+		got = append(got, finding{issue.Rule, issue.Severity, issue.Path})
+	}
+	want := []finding{
+		{RuleHTMLLang, SeverityError, "html"},
+		{RuleImageAlt, SeverityError, "html > body > img:nth-of-type(1)"},
+		{RuleHeadingOrder, SeverityWarning, "html > body > h3"},
+		{RuleLinkName, SeverityError, "html > body > a:nth-of-type(1)"},
+		{RuleButtonName, SeverityError, "html > body > button:nth-of-type(2)"},
+		// id="login" is used twice, so paths through it go up to the root
+		{RuleLabel, SeverityError, "html > body > form#login > input:nth-of-type(2)"},
+		{RuleDuplicateID, SeverityWarning, "html > body > div#login"},
+		{RuleTabindex, SeverityWarning, "html > body > p:nth-of-type(1)"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Issues =\n%v\nwant\n%v", got, want)
+	}
+	if len(a.Issues) == len(want) && !strings.HasSuffix(a.Issues[6].Message, "html > body > form#login") {
+		t.Errorf("duplicate id message = %q; want it to point at the form", a.Issues[6].Message)
+	}
+	if a.Errors != 5 || a.Warnings != 3 {
+		t.Errorf("Errors = %d, Warnings = %d; want 5 and 3", a.Errors, a.Warnings)
+	}
+}
+
+func TestAnalyzePage_Accessibility_LabelAfterControl(t *testing.T) {
+	page := `<html lang="en"><body><input id="q"><label for="q">Search</label></body></html>`
+	result, err := AnalyzePage(strings.NewReader(page), "")
+	if err != nil {
+		t.Fatalf("AnalyzePage: %v", err)
+	}
+	if issues := result.Accessibility.Issues; len(issues) != 0 {
+		t.Errorf("Issues = %+v; want none", issues)
+	}
+}
@@ -1,44 +1,131 @@
 package analyzer
 
 import (
-	"golang.org/x/net/html" 
-	"io"                   
-	"net/http"              
-	"net/url"              
-	"strings"               
-	"sync"           
-	"time"  
+	"context"
+
+	"golang.org/x/net/html"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
 )
 
 // AnalysisResult holds everything we learn about a webpage
 type AnalysisResult struct {
-	HTMLVersion  string          // e.g., "HTML5", "HTML 4.01", or "Unknown"
-	Title        string          // Page <title> content
-	Headings     map[string]int  // Count of <h1>, <h2>, etc. → e.g., "h1": 2
-	Links        Links           // Breakdown of internal/external/inaccessible links
-	HasLoginForm bool            // Does the page likely have a login form?
+	HTMLVersion    string         `json:"html_version"`           // e.g., "HTML5", "HTML 4.01 Strict", or "Unknown"
+	Doctype        Doctype        `json:"doctype"`                // Version, variant, identifiers and quirks mode
+	Title          string         `json:"title"`                  // Page <title> content
+	Headings       map[string]int `json:"headings"`               // Count of <h1>, <h2>, etc. → e.g., "h1": 2
+	Links          Links          `json:"links"`                  // Link classification + health, and per-link details
+	HasLoginForm   bool           `json:"has_login_form"`         // Does the page likely have a login form?
+	Forms          []FormReport   `json:"forms"`                  // Every form with its detected purpose (see forms.go)
+	FinalURL       string         `json:"final_url,omitempty"`    // Where the page fetch ended up after redirects (empty if none)
+	ContentType    string         `json:"content_type,omitempty"` // What the fetched page was analyzed as: text/html or application/xhtml+xml
+	Fetch          *FetchInfo     `json:"fetch,omitempty"`        // Status, headers and timings of the download (nil for uploaded HTML)
+	Encoding       *Encoding      `json:"encoding,omitempty"`     // Detected charset and where it came from (nil for uploaded HTML)
+	Security       *Security      `json:"security,omitempty"`     // Graded security headers, cookies and TLS (nil for uploaded HTML)
+	SEO            SEO            `json:"seo"`                    // Meta description, canonical, Open Graph... (+ issues)
+	StructuredData StructuredData `json:"structured_data"`        // schema.org items from JSON-LD, microdata and RDFa
+	Accessibility  Accessibility  `json:"accessibility"`          // Missing alt/labels, skipped headings... (see accessibility.go)
+	MixedContent   MixedContent   `json:"mixed_content"`          // http:// resources and form actions on an https page
 }
 
-// Links categorizes all <a href=""> links on the page
+// Links describes all <a href=""> links on the page along two independent axes:
+// - classification: where does the link point? (internal / external / unclassified)
+// - health:         does it work?               (ok / broken / unchecked)
+// Every link is counted exactly once on each axis.
 type Links struct {
-	Internal     int // Links to same domain (e.g., /about → yoursite.com/about)
-	External     int // Links to other domains (e.g., google.com)
-	Inaccessible int // Links that are broken or can't be checked
+	// === Classification ===
+	Internal     int `json:"internal"`     // Links to same domain (e.g., /about → yoursite.com/about)
+	External     int `json:"external"`     // Links to other domains (e.g., google.com)
+	Unclassified int `json:"unclassified"` // Unparseable or non-web hrefs (mailto:, javascript:, ...)
+
+	// === Health ===
+	OK        int `json:"ok"`        // Answered with a non-error status
+	Broken    int `json:"broken"`    // Network error or HTTP 4xx/5xx
+	Unchecked int `json:"unchecked"` // Never requested (e.g. can't be resolved to an http(s) URL)
+
+	SkippedByRobots int `json:"skipped_by_robots"` // Unchecked because robots.txt disallows them
+
+	// === Cross-tab: broken links by classification ===
+	InternalBroken int `json:"internal_broken"`
+	ExternalBroken int `json:"external_broken"`
+
+	Items []LinkReport `json:"items"` // One entry per <a href>, in document order
+}
+
+// LinkKind is the classification of a link
+type LinkKind string
+
+const (
+	LinkInternal     LinkKind = "internal"     // Same host as the page
+	LinkExternal     LinkKind = "external"     // Another host
+	LinkUnclassified LinkKind = "unclassified" // Can't tell (unparseable, no host, ...)
+)
+
+// LinkHealth is the result of checking a link
+type LinkHealth string
+
+const (
+	LinkOK        LinkHealth = "ok"        // Responded with < 400
+	LinkBroken    LinkHealth = "broken"    // Error or >= 400
+	LinkUnchecked LinkHealth = "unchecked" // We didn't (or couldn't) request it
+)
+
+// LinkReport is everything we learned about one <a href="..."> link
+type LinkReport struct {
+	Href      string     `json:"href"`                // Raw href as written in the page, e.g. "/about"
+	URL       string     `json:"url,omitempty"`       // Resolved absolute URL, e.g. "https://site.com/about"
+	Kind      LinkKind   `json:"kind"`                // internal / external / unclassified
+	Health    LinkHealth `json:"health"`              // ok / broken / unchecked
+	Status    int        `json:"status,omitempty"`    // Final HTTP status after redirects (0 = no response)
+	Method    string     `json:"method,omitempty"`    // Request that gave the final answer: "HEAD" or "GET"
+	FinalURL  string     `json:"final_url,omitempty"` // Where the redirects ended up (empty if none)
+	Redirects []Redirect `json:"redirects,omitempty"` // Every redirect hop, in order
+	Error     string     `json:"error,omitempty"`     // Why the link is broken or unchecked
+	LatencyMS int64      `json:"latency_ms"`          // How long the check took
+	Text      string     `json:"text"`                // Anchor text, e.g. "About us"
+}
+
+// rawLink is a link as found during traversal, before it is checked
+type rawLink struct {
+	Href string
+	Text string
+}
+
+// Options tweak how a page is analyzed. The zero value is the default behaviour.
+type Options struct {
+	Hooks Hooks // Progress callbacks (jobs, live streams...)
+
+	// SkipLinkCheck resolves and classifies links but never requests them:
+	// every link ends up "unchecked". Useful for HTML that isn't deployed yet.
+	SkipLinkCheck bool
+
+	// Context stops the link checks when it's cancelled (e.g. the client of a
+	// live stream went away): links not checked yet stay "unchecked".
+	// nil = never cancelled. AnalyzeURL sets it to its own ctx.
+	Context context.Context
 }
 
-// httpClient is a reusable HTTP client with:
-// - 5-second timeout (don't hang forever)
-// - No redirect following (we just want to know if link works, not where it goes)
-var httpClient = &http.Client{
-	Timeout: 5 * time.Second,
-	CheckRedirect: func(req *http.Request, via []*http.Request) error {
-		return http.ErrUseLastResponse // Stop after first response, don't follow 301/302
-	},
+// Hooks let callers watch an analysis while it runs. All fields are optional.
+// Callbacks are never called concurrently, so they don't need their own locking.
+type Hooks struct {
+	// OnParsed fires once the DOM is parsed, before any link is checked.
+	// doc has everything except Links; linkCount is how many links will follow.
+	OnParsed func(doc AnalysisResult, linkCount int)
+
+	// OnLinkChecked fires once per link (checked or skipped), in completion order.
+	OnLinkChecked func(link LinkReport, done, total int)
 }
 
 // Main function: Analyze HTML from a reader (could be file, HTTP response, etc.)
 // pageURL is the original URL of the page (needed to resolve relative links)
 func AnalyzePage(body io.Reader, pageURL string) (*AnalysisResult, error) {
+	return AnalyzePageWithOptions(body, pageURL, Options{})
+}
+
+// AnalyzePageWithOptions is AnalyzePage with progress hooks and other options
+func AnalyzePageWithOptions(body io.Reader, pageURL string, opts Options) (*AnalysisResult, error) {
 	// Parse the raw HTML into a DOM tree (like in browser dev tools)
 	doc, err := html.Parse(body)
 	if err != nil {
@@ -51,17 +138,20 @@ func AnalyzePage(body io.Reader, pageURL string) (*AnalysisResult, error) {
 	}
 
 	// === DETECT HTML VERSION ===
-	// Look at the very first node: it should be <!DOCTYPE ...>
-	if doc.FirstChild != nil && doc.FirstChild.Type == html.DoctypeNode {
-		// Example: "<!doctype html>" → we just grab "html" and uppercase it
-		result.HTMLVersion = strings.ToUpper(doc.FirstChild.Data)
-	} else {
-		result.HTMLVersion = "Unknown" // No doctype = old or broken HTML
-	}
+	// Read the <!DOCTYPE ...> public/system identifiers (see doctype.go)
+	// e.g. "-//W3C//DTD HTML 4.01//EN" → "HTML 4.01 Strict", <!doctype html> → "HTML5"
+	result.Doctype = detectDoctype(doc)
+	result.HTMLVersion = result.Doctype.String()
 
 	// === PREPARE VARIABLES FOR TRAVERSAL ===
-	var links []string   // Collect all href values
-	var hasLogin bool    // Will be true if we find a login-like form
+	var links []rawLink         // Collect all href values (+ anchor text)
+	var forms []FormReport      // One report per <form>
+	var standalone []*html.Node // Form fields outside any <form>
+	seo := newSEOCollector()
+	a11y := newA11yCollector()
+	// <base href="..."> changes what every relative URL resolves against
+	base := baseHref(doc)
+	mixed := newMixedCollector(pageURL, base)
 
 	// === TRAVERSE THE HTML TREE ===
 	// This is a recursive function that walks through every node in the DOM
@@ -71,6 +161,9 @@ func AnalyzePage(body io.Reader, pageURL string) (*AnalysisResult, error) {
 		if n.Type == html.ElementNode {
 			tag := strings.ToLower(n.Data) // e.g., "H1" → "h1"
 
+			// Accessibility checks look at every element (see accessibility.go)
+			a11y.element(n)
+
 			switch tag {
 			case "title":
 				// <title>Page Title</title> → grab the text inside
@@ -78,72 +171,42 @@ func AnalyzePage(body io.Reader, pageURL string) (*AnalysisResult, error) {
 					result.Title = n.FirstChild.Data
 				}
 
+			case "meta":
+				// <meta name="description" content="..."> and friends (see seo.go)
+				seo.meta(n)
+
+			case "link":
+				// <link rel="canonical">, <link rel="alternate" hreflang="...">
+				seo.link(n)
+
 			case "h1", "h2", "h3", "h4", "h5", "h6":
 				// Count how many of each heading we see
 				// Use lowercase keys so "H1" and "h1" don't get counted separately
 				result.Headings[tag]++
 
 			case "a":
-				// Found a link: <a href="..."> → extract href attribute
+				// Found a link: <a href="..."> → extract href attribute + visible text
 				for _, attr := range n.Attr {
 					if attr.Key == "href" {
-						links = append(links, attr.Val) // Save the raw href
+						links = append(links, rawLink{Href: attr.Val, Text: textContent(n)})
 					}
 				}
 
 			case "form":
-				// Very simple login form detection:
-				// Look for:
-				// 1. An <input type="password">
-				// 2. An <input> with name/email/login/etc. + type=text/email
-				var hasPassword, hasUser bool
-
-				// Recursively check all <input> inside this form
-				var checkInputs func(*html.Node)
-				checkInputs = func(m *html.Node) {
-					if m.Type == html.ElementNode && strings.ToLower(m.Data) == "input" {
-						var inputType, inputName string
-
-						// Loop through all attributes of this input
-						for _, attr := range m.Attr {
-							key := strings.ToLower(attr.Key)
-							if key == "type" {
-								inputType = strings.ToLower(attr.Val)
-							}
-							if key == "name" {
-								inputName = strings.ToLower(attr.Val)
-							}
-						}
-
-						// Check for password field
-						if inputType == "password" {
-							hasPassword = true
-						}
-
-						// Check for username/email field by type + name
-						if (inputType == "email" || inputType == "text") &&
-							(strings.Contains(inputName, "user") ||
-								strings.Contains(inputName, "email") ||
-								strings.Contains(inputName, "login") ||
-								strings.Contains(inputName, "username")) {
-							hasUser = true
-						}
-					}
-
-					// Keep digging into child nodes
-					for c := m.FirstChild; c != nil; c = c.NextSibling {
-						checkInputs(c)
-					}
-				}
-
-				// Start checking inputs inside this form
-				checkInputs(n)
-
-				// If both user + password fields exist → probably a login form
-				if hasPassword && hasUser {
-					hasLogin = true
+				// Work out what the form is for: login, signup, search... (see forms.go)
+				report := classifyForm(n, false)
+				forms = append(forms, report)
+				mixed.form(n, report) // Credential forms posting to http get called out
+
+			case "input", "select", "textarea":
+				// Fields outside any <form> (SPA-style markup) are grouped after the walk
+				if !insideForm(n) && !strings.EqualFold(attrValue(n, "type"), "hidden") {
+					standalone = append(standalone, n)
 				}
 			}
+
+			// http:// scripts, images, form actions... on an https page (see mixedcontent.go)
+			mixed.element(n)
 		}
 
 		// Go deeper: visit all children of current node
@@ -155,99 +218,259 @@ func AnalyzePage(body io.Reader, pageURL string) (*AnalysisResult, error) {
 	// Start traversal from the root of the document
 	traverse(doc)
 
+	// === CLASSIFY FORMLESS FIELDS ===
+	// Group them by the element that holds them together (see standaloneContainer)
+	seenContainer := make(map[*html.Node]bool)
+	for _, field := range standalone {
+		container := standaloneContainer(field)
+		if container == nil || seenContainer[container] {
+			continue
+		}
+		seenContainer[container] = true
+		// Without a <form> around them, only groups that look like something are worth reporting
+		if report := classifyForm(container, true); report.Purpose != FormOther {
+			forms = append(forms, report)
+		}
+	}
+
 	// Save final results
-	result.HasLoginForm = hasLogin
-	result.Links = analyzeLinks(links, pageURL) // Now classify and check all links
+	result.Forms = forms
+	if result.Forms == nil {
+		result.Forms = []FormReport{}
+	}
+	for _, f := range result.Forms {
+		if f.Purpose == FormLogin && f.Confidence >= loginMinConfidence {
+			result.HasLoginForm = true
+		}
+	}
+	result.SEO = seo.build()
+	result.Accessibility = a11y.build()
+	result.MixedContent = mixed.build()
+	result.StructuredData = extractStructuredData(doc) // JSON-LD, microdata, RDFa (see structured.go)
+
+	// Tell listeners the document part is ready (a copy – Links is still being filled)
+	if opts.Hooks.OnParsed != nil {
+		opts.Hooks.OnParsed(*result, len(links))
+	}
+
+	result.Links = analyzeLinks(links, pageURL, base, opts) // Now classify and check all links
 
 	return result, nil
 }
 
-// analyzeLinks takes raw hrefs and the page's base URL, then:
+// analyzeLinks takes raw links and the page's URL, then:
 // 1. Converts relative → absolute URLs
-// 2. Classifies internal vs external
-// 3. Checks each link with HTTP HEAD request (fast, no body download)
-// 4. Counts inaccessible (404, timeout, etc.)
-func analyzeLinks(links []string, baseURL string) Links {
-	// Parse the main page URL (e.g., "https://example.com/path")
-	parsedBase, err := url.Parse(baseURL)
-	if err != nil || parsedBase == nil || parsedBase.Host == "" {
-		// If base URL is garbage, assume ALL links are inaccessible
-		return Links{Inaccessible: len(links)}
-	}
-
-	// Thread-safe counters (because we use goroutines)
+// 2. Classifies internal / external / unclassified
+// 3. Checks each http(s) link (HEAD, GET fallback, redirects – see checkLink)
+// 4. Records a LinkReport per link with its health (ok / broken / unchecked)
+// Links resolve against base (the page's <base href>, "" if none) but are
+// classified against the page's own host.
+// opts.Hooks.OnLinkChecked (if set) is told about every link as it finishes;
+// with opts.SkipLinkCheck, step 3 is skipped.
+func analyzeLinks(links []rawLink, pageURL, base string, opts Options) Links {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// One report per link, in document order.
+	// Each goroutine only writes its own slot, so no lock is needed.
+	// Until proven otherwise a link is unclassified and unchecked.
+	reports := make([]LinkReport, len(links))
+	for i, l := range links {
+		reports[i] = LinkReport{Href: l.Href, Text: l.Text, Kind: LinkUnclassified, Health: LinkUnchecked}
+	}
+
+	// Progress reporting: count finished links under a lock so
+	// OnLinkChecked calls never overlap (goroutines finish in any order)
 	var mu sync.Mutex
-	var wg sync.WaitGroup
+	checked := 0
+	finished := func(report *LinkReport) {
+		if opts.Hooks.OnLinkChecked == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		checked++
+		opts.Hooks.OnLinkChecked(*report, checked, len(reports))
+	}
+
+	// Parse the main page URL (e.g., "https://example.com/path").
+	// Without one (uploaded HTML with no base URL) absolute links are still
+	// checked, but they can't be internal or external, and relative links
+	// can't be resolved at all.
+	page, err := url.Parse(pageURL)
+	if err != nil || page.Host == "" {
+		page = nil
+	}
+	resolveBase := page
+	if b, err := url.Parse(base); base != "" && err == nil {
+		if page != nil {
+			resolveBase = page.ResolveReference(b)
+		} else if b.IsAbs() && b.Host != "" {
+			resolveBase = b // An absolute <base href> works on its own
+		}
+	}
 
-	internalCount := 0
-	externalCount := 0
-	inaccCount := 0
+	var wg sync.WaitGroup
 
 	// Loop through every link found on the page
-	for _, raw := range links {
+	for i := range reports {
+		report := &reports[i]
+
 		// Parse the raw href (could be "/about", "https://google.com", "#top", etc.)
-		parsed, err := url.Parse(raw)
+		parsed, err := url.Parse(report.Href)
 		if err != nil {
-			inaccCount++ // Can't even parse → inaccessible
+			report.Error = "invalid URL" // Can't even parse → unclassified, unchecked
+			finished(report)
 			continue
 		}
 
 		// Convert to full absolute URL: "/about" → "https://example.com/about"
-		abs := parsedBase.ResolveReference(parsed)
+		abs := parsed
+		if resolveBase != nil {
+			abs = resolveBase.ResolveReference(parsed)
+		} else if !parsed.IsAbs() {
+			report.Error = "relative URL without a page URL" // Nothing to resolve it against
+			finished(report)
+			continue
+		}
+		report.URL = abs.String()
 
-		// Skip invalid URLs (missing scheme or host)
-		if abs.Scheme == "" || abs.Host == "" {
-			inaccCount++
+		// Skip URLs we can't request (mailto:, javascript:, missing host...)
+		if (abs.Scheme != "http" && abs.Scheme != "https") || abs.Host == "" {
+			report.Error = "not an http(s) URL"
+			finished(report)
 			continue
 		}
 
-		// Is this link on the same domain?
-		isInternal := abs.Host == parsedBase.Host
+		// Is this link on the same domain? (unknown without a page URL)
+		if page != nil && abs.Host == page.Host {
+			report.Kind = LinkInternal
+		} else if page != nil {
+			report.Kind = LinkExternal
+		}
+
+		if opts.SkipLinkCheck {
+			report.Error = "link checking disabled"
+			finished(report)
+			continue
+		}
+		if ctx.Err() != nil {
+			// Nobody is waiting for the answer anymore
+			report.Error = linkCancelledMessage
+			finished(report)
+			continue
+		}
 
-		// === CONCURRENCY CONTROL ===
-		// Acquire() / Release() likely limit how many requests run at once
-		// (Not defined here — probably a semaphore in real code)
-		Acquire()
+		// Launch a goroutine to check this one link.
+		// checkLink holds a worker-pool token only while its request runs,
+		// not while it waits for the host (see links.go)
 		wg.Add(1)
+		go func(report *LinkReport) {
+			defer wg.Done() // Mark this task done when finished
 
-		// Launch a goroutine to check this one link
-		go func(u *url.URL, isInternal bool) {
-			defer wg.Done()   // Mark this task done when finished
-			defer Release()   // Free up slot for next request
-
-			// Use HEAD request: fast way to check if link works (no HTML body)
-			resp, err := httpClient.Head(u.String())
-
-			// Lock counters while updating (thread safety)
-			mu.Lock()
-			defer mu.Unlock()
-
-			if err != nil || (resp != nil && resp.StatusCode >= 400) {
-				// Network error OR 404, 500, etc. → inaccessible
-				inaccCount++
-			} else {
-				// Link works!
-				if isInternal {
-					internalCount++
-				} else {
-					externalCount++
-				}
+			// HEAD first, GET fallback, redirects followed (see links.go)
+			checkLink(ctx, report)
+			finished(report)
+		}(report)
+	}
+
+	// Wait for all link checks to finish
+	wg.Wait()
+
+	return summarizeLinks(reports)
+}
+
+// summarizeLinks turns per-link reports into the Links counters.
+// Classification and health are counted independently.
+func summarizeLinks(reports []LinkReport) Links {
+	links := Links{Items: reports}
+	for _, r := range reports {
+		switch r.Kind {
+		case LinkInternal:
+			links.Internal++
+		case LinkExternal:
+			links.External++
+		default:
+			links.Unclassified++
+		}
+
+		switch r.Health {
+		case LinkOK:
+			links.OK++
+		case LinkBroken:
+			links.Broken++
+			if r.Kind == LinkInternal {
+				links.InternalBroken++
+			} else if r.Kind == LinkExternal {
+				links.ExternalBroken++
 			}
+		default:
+			links.Unchecked++
+			if r.Error == robotsSkipMessage {
+				links.SkippedByRobots++
+			}
+		}
+	}
+	return links
+}
 
-			// Always close response body to avoid leaks
-			if resp != nil {
-				resp.Body.Close()
+// baseHref returns the href of the document's <base> element ("" if none).
+// Like browsers, only the first <base> with an href counts, and it applies
+// to the whole document – even URLs that come before it.
+func baseHref(doc *html.Node) string {
+	var find func(*html.Node) string
+	find = func(n *html.Node) string {
+		if n.Type == html.ElementNode && n.Data == "base" && hasAttr(n, "href") {
+			return strings.TrimSpace(attrValue(n, "href"))
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if href := find(c); href != "" {
+				return href
 			}
-		}(abs, isInternal) // Pass variables into goroutine
+		}
+		return ""
 	}
+	return find(doc)
+}
 
-	// Wait for all link checks to finish
-	wg.Wait()
+// attrValue returns the value of attribute key on n ("" if it isn't set).
+// The parser already lowercases attribute names, so key must be lowercase.
+func attrValue(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
 
-	// Return final counts
-	return Links{
-		Internal:     internalCount,
-		External:     externalCount,
-		Inaccessible: inaccCount,
+// hasAttr reports whether n has attribute key at all – for boolean
+// attributes like itemscope, which usually have no value
+func hasAttr(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return true
+		}
 	}
-}
\ No newline at end of file
+	return false
+}
+
+// textContent returns all text inside n with whitespace collapsed,
+// e.g. "<a> Read <b>more</b>\n</a>" → "Read more"
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(m *html.Node) {
+		if m.Type == html.TextNode {
+			sb.WriteString(m.Data)
+			sb.WriteByte(' ')
+		}
+		for c := m.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
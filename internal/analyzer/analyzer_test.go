@@ -1,8 +1,10 @@
 package analyzer
 
 import (
+	"context"
 	"io"
 	"net/http"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -44,8 +46,8 @@ func TestAnalyzePage_BasicsAndLinks(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if result.HTMLVersion != "HTML" {
-		t.Errorf("HTMLVersion = %q; want %q", result.HTMLVersion, "HTML")
+	if result.HTMLVersion != "HTML5" {
+		t.Errorf("HTMLVersion = %q; want %q", result.HTMLVersion, "HTML5")
 	}
 	if result.Title != "Test Page" {
 		t.Errorf("Title = %q; want %q", result.Title, "Test Page")
@@ -62,15 +64,30 @@ func TestAnalyzePage_BasicsAndLinks(t *testing.T) {
 		}
 	}
 
-	// 1 internal (200), 1 external (200), 1 inaccessible (invalid)
-	if result.Links.Internal != 1 {
-		t.Errorf("Internal = %d; want 1", result.Links.Internal)
+	// Classification: 1 internal, 1 external, 1 unclassified (invalid)
+	// Health:         2 ok (200), 1 unchecked (invalid), 0 broken
+	wantLinks := Links{Internal: 1, External: 1, Unclassified: 1, OK: 2, Unchecked: 1}
+	gotLinks := result.Links
+	gotLinks.Items = nil
+	if !reflect.DeepEqual(gotLinks, wantLinks) {
+		t.Errorf("Links = %+v; want %+v", gotLinks, wantLinks)
 	}
-	if result.Links.External != 1 {
-		t.Errorf("External = %d; want 1", result.Links.External)
+
+	// Per-link report: one item per <a>, in document order
+	wantItems := []LinkReport{
+		{Href: "/internal", URL: "https://mydomain.com/internal", Kind: LinkInternal, Health: LinkOK, Status: 200, Method: "HEAD", Text: "int"},
+		{Href: "https://example.com/ext", URL: "https://example.com/ext", Kind: LinkExternal, Health: LinkOK, Status: 200, Method: "HEAD", Text: "ext"},
+		{Href: ":invalid", Kind: LinkUnclassified, Health: LinkUnchecked, Error: "invalid URL", Text: "bad"},
+	}
+	if len(result.Links.Items) != len(wantItems) {
+		t.Fatalf("Items = %+v; want %d items", result.Links.Items, len(wantItems))
 	}
-	if result.Links.Inaccessible != 1 {
-		t.Errorf("Inaccessible = %d; want 1", result.Links.Inaccessible)
+	for i, want := range wantItems {
+		got := result.Links.Items[i]
+		got.LatencyMS = 0 // timing is not deterministic
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Items[%d] = %+v; want %+v", i, got, want)
+		}
 	}
 
 	// No login form here
@@ -79,6 +96,30 @@ func TestAnalyzePage_BasicsAndLinks(t *testing.T) {
 	}
 }
 
+func TestSortLinkReports(t *testing.T) {
+	items := []LinkReport{
+		{Href: "/b", Status: 404},
+		{Href: "/a", Status: 200},
+		{Href: "/c", Status: 500},
+	}
+
+	sorted, err := SortLinkReports(items, "status", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sorted[0].Href != "/c" || sorted[1].Href != "/b" || sorted[2].Href != "/a" {
+		t.Errorf("sorted by status desc = %+v", sorted)
+	}
+	// The input must not be reordered (it may be shared with the cache)
+	if items[0].Href != "/b" {
+		t.Errorf("input was modified: %+v", items)
+	}
+
+	if _, err := SortLinkReports(items, "nope", false); err == nil {
+		t.Errorf("expected error for unknown sort key")
+	}
+}
+
 func TestAnalyzePage_LoginFormDetection(t *testing.T) {
 	// Save original, restore after
 	oldClient := httpClient
@@ -122,7 +163,171 @@ func TestAnalyzePage_LoginFormDetection(t *testing.T) {
 	}
 
 	// With no anchors, all link counts should be zero
-	if result.Links.Internal != 0 || result.Links.External != 0 || result.Links.Inaccessible != 0 {
+	if result.Links.Internal != 0 || result.Links.External != 0 || result.Links.Unclassified != 0 ||
+		result.Links.OK != 0 || result.Links.Broken != 0 || result.Links.Unchecked != 0 {
 		t.Errorf("Links = %+v; want all zeros", result.Links)
 	}
 }
+
+func TestAnalyzePage_BrokenLinksKeepTheirClassification(t *testing.T) {
+	// Save original, restore after
+	oldClient := httpClient
+	defer func() { httpClient = oldClient }()
+
+	// Mock: anything with "broken" in the path is a 404, the rest 200
+	httpClient = &http.Client{
+		Transport: mockTransport(func(req *http.Request) *http.Response {
+			status := http.StatusOK
+			if strings.Contains(req.URL.Path, "broken") {
+				status = http.StatusNotFound
+			}
+			return &http.Response{
+				StatusCode: status,
+				Status:     http.StatusText(status),
+				Body:       io.NopCloser(strings.NewReader("")),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	htmlStr := `<html><body>
+<a href="/ok">ok</a>
+<a href="/broken">int broken</a>
+<a href="https://other.com/broken">ext broken</a>
+<a href="mailto:me@example.com">mail</a>
+</body></html>`
+
+	result, err := AnalyzePage(strings.NewReader(htmlStr), "https://mydomain.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A broken internal link is still internal – it just also counts as broken
+	want := Links{
+		Internal: 2, External: 1, Unclassified: 1,
+		OK: 1, Broken: 2, Unchecked: 1,
+		InternalBroken: 1, ExternalBroken: 1,
+	}
+	got := result.Links
+	got.Items = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Links = %+v; want %+v", got, want)
+	}
+}
+
+func TestCheckLink_HeadFallbackAndRedirects(t *testing.T) {
+	// Save originals, restore after
+	oldClient, oldMax := httpClient, MaxLinkRedirects
+	defer func() { httpClient, MaxLinkRedirects = oldClient, oldMax }()
+	MaxLinkRedirects = 3
+
+	// Mock server behaviour per path:
+	// /nohead → 405 on HEAD, 206 on ranged GET
+	// /old    → 301 to /new (relative Location), /new → 200
+	// /loop   → 302 to itself forever
+	// /gone   → 404
+	// /norange → 405 on HEAD, 416 on ranged GET, 200 on plain GET
+	var sawRange bool
+	httpClient = &http.Client{
+		CheckRedirect: oldClient.CheckRedirect,
+		Transport: mockTransport(func(req *http.Request) *http.Response {
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("")),
+				Header:     make(http.Header),
+				Request:    req,
+			}
+			switch req.URL.Path {
+			case "/nohead":
+				if req.Method == http.MethodHead {
+					resp.StatusCode = http.StatusMethodNotAllowed
+				} else {
+					sawRange = req.Header.Get("Range") == "bytes=0-0"
+					resp.StatusCode = http.StatusPartialContent
+				}
+			case "/old":
+				resp.StatusCode = http.StatusMovedPermanently
+				resp.Header.Set("Location", "/new")
+			case "/loop":
+				resp.StatusCode = http.StatusFound
+				resp.Header.Set("Location", "/loop")
+			case "/gone":
+				resp.StatusCode = http.StatusNotFound
+				resp.Status = "404 Not Found"
+			case "/norange":
+				switch {
+				case req.Method == http.MethodHead:
+					resp.StatusCode = http.StatusMethodNotAllowed
+				case req.Header.Get("Range") != "":
+					resp.StatusCode = http.StatusRequestedRangeNotSatisfiable
+				}
+			}
+			return resp
+		}),
+	}
+
+	check := func(u string) LinkReport {
+		r := LinkReport{URL: u}
+		checkLink(context.Background(), &r)
+		return r
+	}
+
+	t.Run("HEAD rejected falls back to ranged GET", func(t *testing.T) {
+		r := check("https://site.com/nohead")
+		if r.Health != LinkOK || r.Status != http.StatusPartialContent || r.Method != http.MethodGet {
+			t.Errorf("got %+v; want ok / 206 / GET", r)
+		}
+		if !sawRange {
+			t.Errorf("GET fallback did not send Range: bytes=0-0")
+		}
+	})
+
+	t.Run("redirect is followed and recorded", func(t *testing.T) {
+		r := check("https://site.com/old")
+		if r.Health != LinkOK || r.Status != http.StatusOK {
+			t.Fatalf("got %+v; want ok / 200", r)
+		}
+		if r.FinalURL != "https://site.com/new" {
+			t.Errorf("FinalURL = %q; want https://site.com/new", r.FinalURL)
+		}
+		want := []Redirect{{URL: "https://site.com/old", Status: http.StatusMovedPermanently}}
+		if !reflect.DeepEqual(r.Redirects, want) {
+			t.Errorf("Redirects = %+v; want %+v", r.Redirects, want)
+		}
+	})
+
+	t.Run("redirect loop stops at the limit", func(t *testing.T) {
+		r := check("https://site.com/loop")
+		if r.Health != LinkBroken || !strings.Contains(r.Error, "too many redirects") {
+			t.Errorf("got %+v; want broken with too many redirects", r)
+		}
+		if len(r.Redirects) != MaxLinkRedirects+1 {
+			t.Errorf("recorded %d hops; want %d", len(r.Redirects), MaxLinkRedirects+1)
+		}
+	})
+
+	t.Run("404 is broken without fallback", func(t *testing.T) {
+		r := check("https://site.com/gone")
+		if r.Health != LinkBroken || r.Status != http.StatusNotFound || r.Method != http.MethodHead {
+			t.Errorf("got %+v; want broken / 404 / HEAD", r)
+		}
+	})
+
+	t.Run("416 on ranged GET is retried without Range", func(t *testing.T) {
+		r := check("https://site.com/norange")
+		if r.Health != LinkOK || r.Status != http.StatusOK || r.Method != http.MethodGet {
+			t.Errorf("got %+v; want ok / 200 / GET", r)
+		}
+	})
+
+	t.Run("MaxLinkRedirects=0 reports the redirect without following it", func(t *testing.T) {
+		old := MaxLinkRedirects
+		MaxLinkRedirects = 0
+		defer func() { MaxLinkRedirects = old }()
+
+		r := check("https://site.com/old")
+		if r.Health != LinkOK || r.Status != http.StatusMovedPermanently || len(r.Redirects) != 0 || r.FinalURL != "" {
+			t.Errorf("got %+v; want ok / 301, no hops followed", r)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxAPIBodyBytes caps JSON request bodies (1 MB is plenty for a URL)
+const maxAPIBodyBytes = 1 << 20
+
+// apiAnalyzeRequest is the JSON body accepted by POST /api/v1/analyze
+// Example: {"url": "https://example.com", "refresh": true}
+type apiAnalyzeRequest struct {
+	URL     string `json:"url"`
+	Refresh bool   `json:"refresh"` // true = ignore any cached result
+}
+
+// apiAnalyzeResponse is what a successful call returns.
+// The result is wrapped (instead of returned bare) so we can add
+// metadata next to it later without breaking clients.
+type apiAnalyzeResponse struct {
+	URL    string          `json:"url"`
+	Cache  CacheInfo       `json:"cache"`
+	Result *AnalysisResult `json:"result"`
+}
+
+// apiErrorResponse is the body of every non-2xx API answer
+// Example: {"error": {"status": 400, "code": "invalid_url", "message": "Invalid URL format"}}
+type apiErrorResponse struct {
+	Error *AnalysisError `json:"error"`
+}
+
+// APIAnalyzeHandler serves POST /api/v1/analyze[?sort=<key>&order=desc].
+// Same pipeline as AnalyzeHandler (see AnalyzeURL), but speaks JSON
+// and uses real HTTP status codes instead of a 200 error page.
+func APIAnalyzeHandler(log *logrus.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// === STEP 1: Only allow POST ===
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeAPIError(w, newAnalysisError(http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "Method not allowed"))
+			return
+		}
+
+		// === STEP 2: Decode the JSON body ===
+		var req apiAnalyzeRequest
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			writeAPIError(w, err)
+			return
+		}
+
+		// A bad ?sort= key is the client's mistake: say so before fetching anything
+		sortKey := r.URL.Query().Get("sort")
+		if sortKey != "" {
+			if err := CheckLinkSortKey(sortKey); err != nil {
+				writeAPIError(w, newAnalysisError(http.StatusBadRequest, ErrCodeBadRequest, "%v", err))
+				return
+			}
+		}
+
+		log.WithFields(logrus.Fields{
+			"url": req.URL,
+			"api": "v1",
+		}).Info("Starting analysis")
+
+		// === STEP 3: Run the shared pipeline ===
+		result, cache, err := AnalyzeURLCached(r.Context(), req.URL, req.Refresh, Options{})
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+
+		// === STEP 4: Optional link ordering: ?sort=status&order=desc ===
+		if sortKey != "" {
+			sorted, _ := SortLinkReports(result.Links.Items, sortKey, r.URL.Query().Get("order") == "desc") // Key checked above
+			// Work on a copy – result may be shared with the cache
+			copied := *result
+			copied.Links.Items = sorted
+			result = &copied
+		}
+
+		// === STEP 5: Send the result back ===
+		writeJSON(w, http.StatusOK, apiAnalyzeResponse{
+			URL:    req.URL,
+			Cache:  cache,
+			Result: result,
+		})
+	}
+}
+
+// decodeJSONBody reads a size-limited JSON body into dst.
+// Returns a 400 *AnalysisError if the body is empty or malformed.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxAPIBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		return newAnalysisError(http.StatusBadRequest, ErrCodeBadRequest, "Invalid JSON body: %v", err)
+	}
+	return nil
+}
+
+// writeJSON sends v as JSON with the given status code
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v) // client may have gone away – nothing to do
+}
+
+// writeAPIError sends err as a typed JSON error object
+func writeAPIError(w http.ResponseWriter, err error) {
+	aerr := asAnalysisError(err)
+	writeJSON(w, aerr.Status, apiErrorResponse{Error: aerr})
+}
@@ -0,0 +1,127 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// doAPIRequest sends body to the API handler and decodes the JSON answer into out
+func doAPIRequest(t *testing.T, h http.Handler, method, body string, out any) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, "/api/v1/analyze", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	h.ServeHTTP(rr, req)
+
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("Content-Type = %q; want application/json", ct)
+	}
+	if out != nil {
+		if err := json.Unmarshal(rr.Body.Bytes(), out); err != nil {
+			t.Fatalf("response is not JSON: %v; body: %s", err, rr.Body.String())
+		}
+	}
+	return rr
+}
+
+func TestAPIAnalyzeHandler(t *testing.T) {
+	h := APIAnalyzeHandler(logrus.New())
+
+	errorCases := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"method not allowed", http.MethodGet, "", http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed},
+		{"malformed JSON", http.MethodPost, "{not json", http.StatusBadRequest, ErrCodeBadRequest},
+		{"missing URL", http.MethodPost, `{}`, http.StatusBadRequest, ErrCodeMissingURL},
+		{"invalid URL", http.MethodPost, `{"url":"not-a-url"}`, http.StatusBadRequest, ErrCodeInvalidURL},
+		{"fetch error", http.MethodPost, `{"url":"http://127.0.0.1:0/x"}`, http.StatusBadGateway, ErrCodeFetchFailed},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var resp apiErrorResponse
+			rr := doAPIRequest(t, h, tc.method, tc.body, &resp)
+
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d; want %d", rr.Code, tc.wantStatus)
+			}
+			if resp.Error == nil || resp.Error.Code != tc.wantCode {
+				t.Fatalf("error = %+v; want code %q", resp.Error, tc.wantCode)
+			}
+			if resp.Error.Status != tc.wantStatus {
+				t.Errorf("error.status = %d; want %d", resp.Error.Status, tc.wantStatus)
+			}
+		})
+	}
+
+	t.Run("non-200 upstream carries upstream status", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		}))
+		defer ts.Close()
+
+		var resp apiErrorResponse
+		rr := doAPIRequest(t, h, http.MethodPost, `{"url":"`+ts.URL+`"}`, &resp)
+
+		if rr.Code != http.StatusBadGateway {
+			t.Fatalf("status = %d; want 502", rr.Code)
+		}
+		if resp.Error.Code != ErrCodeUpstreamStatus {
+			t.Fatalf("code = %q; want %q", resp.Error.Code, ErrCodeUpstreamStatus)
+		}
+		if got := resp.Error.Details["upstream_status"]; got != "404" {
+			t.Errorf("upstream_status = %q; want 404", got)
+		}
+	})
+
+	t.Run("happy path returns AnalysisResult", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`<!doctype html><html><head><title>API Page</title></head><body><h1>x</h1></body></html>`))
+		}))
+		defer ts.Close()
+
+		var resp apiAnalyzeResponse
+		rr := doAPIRequest(t, h, http.MethodPost, `{"url":"`+ts.URL+`"}`, &resp)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d; want 200; body: %s", rr.Code, rr.Body.String())
+		}
+		if resp.URL != ts.URL {
+			t.Errorf("url = %q; want %q", resp.URL, ts.URL)
+		}
+		if resp.Result == nil || resp.Result.Title != "API Page" {
+			t.Fatalf("result = %+v; want title %q", resp.Result, "API Page")
+		}
+		if resp.Result.Headings["h1"] != 1 {
+			t.Errorf("headings = %v; want h1=1", resp.Result.Headings)
+		}
+	})
+	t.Run("unknown sort key is rejected before fetching", func(t *testing.T) {
+		var fetches atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fetches.Add(1)
+		}))
+		defer ts.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/analyze?sort=bogus", strings.NewReader(`{"url":"`+ts.URL+`","refresh":true}`))
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d; want 400; body: %s", rr.Code, rr.Body.String())
+		}
+		if n := fetches.Load(); n != 0 {
+			t.Errorf("page fetched %d times; want 0", n)
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MaxBatchURLs caps how many URLs one batch request may contain
+// (set from BATCH_MAX_URLS in main.go)
+var MaxBatchURLs = 500
+
+// BatchConcurrency is how many pages of a batch are analyzed at once
+// (set from BATCH_CONCURRENCY in main.go). Page fetches and link checks
+// still go through the shared request pool (see workerpool.go).
+var BatchConcurrency = 8
+
+// BatchItem is the outcome for one URL of a batch: Result or Error, never both
+type BatchItem struct {
+	URL    string          `json:"url"`
+	Cache  *CacheInfo      `json:"cache,omitempty"`
+	Result *AnalysisResult `json:"result,omitempty"`
+	Error  *AnalysisError  `json:"error,omitempty"`
+}
+
+// BatchSummary adds up the whole batch
+type BatchSummary struct {
+	Total       int `json:"total"`        // URLs submitted
+	Succeeded   int `json:"succeeded"`    // Analyzed without error
+	Failed      int `json:"failed"`       // Invalid, unreachable, non-200...
+	CacheHits   int `json:"cache_hits"`   // Served from cache
+	BrokenLinks int `json:"broken_links"` // Broken links across all analyzed pages
+}
+
+// BatchReport is the response of POST /api/v1/batch
+type BatchReport struct {
+	Summary BatchSummary `json:"summary"`
+	Items   []BatchItem  `json:"items"` // Same order as the submitted URLs
+}
+
+// apiBatchRequest is the JSON form of a batch: {"urls": ["https://a", "https://b"]}
+type apiBatchRequest struct {
+	URLs    []string `json:"urls"`
+	Refresh bool     `json:"refresh"`
+}
+
+// RunBatch analyzes every URL with at most BatchConcurrency pages in flight.
+// Per-URL failures are recorded in the item; RunBatch itself never fails.
+func RunBatch(ctx context.Context, urls []string, refresh bool) BatchReport {
+	items := analyzeURLs(ctx, urls, refresh, NewWorkerPool(BatchConcurrency))
+	return BatchReport{Summary: summarizeBatch(items), Items: items}
+}
+
+// analyzeURLs runs the cached pipeline for every URL, holding one pool token
+// per page. Items come back in the same order as urls. Shared by batch and crawl.
+func analyzeURLs(ctx context.Context, urls []string, refresh bool, pool *WorkerPool) []BatchItem {
+	items := make([]BatchItem, len(urls))
+	var wg sync.WaitGroup
+
+	for i, u := range urls {
+		pool.Acquire()
+		wg.Add(1)
+
+		// Each goroutine writes only its own slot → no lock needed
+		go func(item *BatchItem, rawURL string) {
+			defer wg.Done()
+			defer pool.Release()
+
+			item.URL = rawURL
+			result, cache, err := AnalyzeURLCached(ctx, rawURL, refresh, Options{})
+			if err != nil {
+				item.Error = asAnalysisError(err)
+				return
+			}
+			item.Result = result
+			item.Cache = &cache
+		}(&items[i], u)
+	}
+	wg.Wait()
+
+	return items
+}
+
+// summarizeBatch counts successes, failures and broken links
+func summarizeBatch(items []BatchItem) BatchSummary {
+	s := BatchSummary{Total: len(items)}
+	for _, item := range items {
+		if item.Error != nil {
+			s.Failed++
+			continue
+		}
+		s.Succeeded++
+		s.BrokenLinks += item.Result.Links.Broken
+		if item.Cache != nil && item.Cache.Hit {
+			s.CacheHits++
+		}
+	}
+	return s
+}
+
+// BatchHandler serves POST /api/v1/batch. The URL list can be sent as:
+// - JSON:       {"urls": ["https://a.com", "https://b.com"], "refresh": false}
+// - Plain text: one URL per line (Content-Type: text/plain)
+// - Upload:     multipart/form-data with a newline-delimited "file" field
+// Blank lines and lines starting with "#" are ignored in the text forms.
+func BatchHandler(log *logrus.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// === STEP 1: Only allow POST ===
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeAPIError(w, newAnalysisError(http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "Method not allowed"))
+			return
+		}
+
+		// === STEP 2: Read the URL list in whatever format it came ===
+		urls, refresh, err := readBatchRequest(w, r)
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		if len(urls) == 0 {
+			writeAPIError(w, newAnalysisError(http.StatusBadRequest, ErrCodeMissingURL, "No URLs given"))
+			return
+		}
+		if len(urls) > MaxBatchURLs {
+			writeAPIError(w, newAnalysisError(http.StatusRequestEntityTooLarge, ErrCodeBatchTooLarge,
+				"Too many URLs: %d (max %d per batch)", len(urls), MaxBatchURLs))
+			return
+		}
+
+		log.WithFields(logrus.Fields{
+			"urls": len(urls),
+		}).Info("Starting batch analysis")
+
+		// === STEP 3: Analyze everything and report ===
+		report := RunBatch(r.Context(), urls, refresh)
+
+		log.WithFields(logrus.Fields{
+			"urls":   report.Summary.Total,
+			"failed": report.Summary.Failed,
+		}).Info("Batch finished")
+
+		writeJSON(w, http.StatusOK, report)
+	}
+}
+
+// readBatchRequest extracts the URL list (and refresh flag) from the request
+func readBatchRequest(w http.ResponseWriter, r *http.Request) ([]string, bool, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	refresh := r.URL.Query().Get("refresh") != ""
+
+	switch mediaType {
+	case "application/json":
+		var req apiBatchRequest
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			return nil, false, err
+		}
+		return req.URLs, req.Refresh || refresh, nil
+
+	case "multipart/form-data":
+		r.Body = http.MaxBytesReader(w, r.Body, maxAPIBodyBytes)
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, false, newAnalysisError(http.StatusBadRequest, ErrCodeBadRequest, "Missing \"file\" upload: %v", err)
+		}
+		defer file.Close()
+		urls, err := readURLLines(file)
+		return urls, refresh || r.FormValue("refresh") != "", err
+
+	default:
+		// text/plain, or anything else: treat the body as newline-delimited
+		r.Body = http.MaxBytesReader(w, r.Body, maxAPIBodyBytes)
+		urls, err := readURLLines(r.Body)
+		return urls, refresh, err
+	}
+}
+
+// readURLLines reads one URL per line, skipping blanks and # comments
+func readURLLines(rd io.Reader) ([]string, error) {
+	var urls []string
+	sc := bufio.NewScanner(rd)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, newAnalysisError(http.StatusBadRequest, ErrCodeBadRequest, "Could not read URL list: %v", err)
+	}
+	return urls, nil
+}
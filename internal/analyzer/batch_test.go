@@ -0,0 +1,113 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestBatchHandler(t *testing.T) {
+	// Save original, restore after – "/dead" links are broken, the rest OK
+	oldClient := httpClient
+	defer func() { httpClient = oldClient }()
+	httpClient = &http.Client{
+		Transport: mockTransport(func(req *http.Request) *http.Response {
+			status := http.StatusOK
+			if req.URL.Path == "/dead" {
+				status = http.StatusNotFound
+			}
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(strings.NewReader("")),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`<title>` + r.URL.Path + `</title><a href="/ok">ok</a><a href="/dead">dead</a>`))
+	}))
+	defer ts.Close()
+
+	h := BatchHandler(logrus.New())
+	post := func(contentType string, body io.Reader) (*httptest.ResponseRecorder, BatchReport) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/batch?refresh=1", body)
+		req.Header.Set("Content-Type", contentType)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		var report BatchReport
+		_ = json.Unmarshal(rr.Body.Bytes(), &report)
+		return rr, report
+	}
+
+	wantSummary := BatchSummary{Total: 3, Succeeded: 2, Failed: 1, BrokenLinks: 2}
+	checkReport := func(t *testing.T, rr *httptest.ResponseRecorder, report BatchReport) {
+		t.Helper()
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d; want 200; body: %s", rr.Code, rr.Body.String())
+		}
+		if report.Summary != wantSummary {
+			t.Errorf("Summary = %+v; want %+v", report.Summary, wantSummary)
+		}
+		// Items keep submission order
+		if len(report.Items) != 3 || report.Items[0].Result == nil || report.Items[0].Result.Title != "/one" {
+			t.Fatalf("Items = %+v; want /one first", report.Items)
+		}
+		if report.Items[2].Error == nil || report.Items[2].Error.Code != ErrCodeUpstreamStatus {
+			t.Errorf("Items[2].Error = %+v; want %s", report.Items[2].Error, ErrCodeUpstreamStatus)
+		}
+	}
+
+	t.Run("JSON array", func(t *testing.T) {
+		body := `{"urls":["` + ts.URL + `/one","` + ts.URL + `/two","` + ts.URL + `/missing"]}`
+		rr, report := post("application/json", strings.NewReader(body))
+		checkReport(t, rr, report)
+	})
+
+	t.Run("newline-delimited text with comments", func(t *testing.T) {
+		body := "# landing pages\n" + ts.URL + "/one\n\n" + ts.URL + "/two\n" + ts.URL + "/missing\n"
+		rr, report := post("text/plain", strings.NewReader(body))
+		checkReport(t, rr, report)
+	})
+
+	t.Run("multipart file upload", func(t *testing.T) {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		fw, _ := mw.CreateFormFile("file", "urls.txt")
+		_, _ = fw.Write([]byte(ts.URL + "/one\n" + ts.URL + "/two\n" + ts.URL + "/missing\n"))
+		mw.Close()
+
+		rr, report := post(mw.FormDataContentType(), &buf)
+		checkReport(t, rr, report)
+	})
+
+	t.Run("empty batch is rejected", func(t *testing.T) {
+		rr, _ := post("application/json", strings.NewReader(`{"urls":[]}`))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d; want 400", rr.Code)
+		}
+	})
+
+	t.Run("oversized batch is rejected", func(t *testing.T) {
+		oldMax := MaxBatchURLs
+		defer func() { MaxBatchURLs = oldMax }()
+		MaxBatchURLs = 2
+
+		rr, _ := post("text/plain", strings.NewReader("https://a.com\nhttps://b.com\nhttps://c.com\n"))
+		if rr.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("status = %d; want 413", rr.Code)
+		}
+	})
+}
@@ -2,43 +2,75 @@ package analyzer
 
 import (
 	"context"
-	"encoding/json"
 	"time"
-	"os"
-	"github.com/redis/go-redis/v9"
 )
 
-var redisClient *redis.Client
-var CACHE_TTL = 1 // Redis TTL
+// Cache is anything that can remember analysis results for a while.
+// Implementations:
+// - RedisCache:  shared between instances (production)
+// - MemoryCache: in-process LRU with TTL (single instance / local runs)
+// - NoopCache:   never stores anything (caching disabled, tests)
+type Cache interface {
+	// Get returns the entry stored under key, or false on a miss.
+	// Backend errors must be reported as a miss, never as a failure.
+	Get(ctx context.Context, key string) (*CacheEntry, bool)
 
-func init() {
-	addr := os.Getenv("REDIS_ADDR")
-	if addr == "" {
-		addr = "localhost:6379" // fallback if env var not set
-	}
+	// Set stores entry under key for ttl. Errors are swallowed –
+	// a cache that can't write just means the next request is slower.
+	Set(ctx context.Context, key string, entry *CacheEntry, ttl time.Duration)
+}
+
+// ResultCache is the cache used by the pipeline.
+// main.go picks the backend (CACHE_BACKEND env var); tests can swap in their own.
+var ResultCache Cache = NoopCache{}
+
+// CacheTTL is how long an analysis stays cached.
+// Override it from main.go (CACHE_TTL env var), e.g. "30m" or "2h".
+var CacheTTL = time.Hour
+
+// CacheEntry is what we actually store:
+// the result plus the time we stored it (so we can report its age)
+type CacheEntry struct {
+	Result   *AnalysisResult `json:"result"`
+	StoredAt time.Time       `json:"stored_at"`
+}
 
-	redisClient = redis.NewClient(&redis.Options{
-		Addr: addr,
-	})
+// CacheInfo tells the caller whether a result was served from cache
+type CacheInfo struct {
+	Hit        bool      `json:"hit"`                // true = no fetch happened
+	StoredAt   time.Time `json:"stored_at,omitzero"` // when the cached entry was written
+	AgeSeconds int64     `json:"age_seconds"`        // how old the cached entry is
+}
+
+// cacheKey builds the key for a URL, e.g. "result:https://example.com"
+func cacheKey(url string) string {
+	return "result:" + url
 }
 
 // GetCachedResult checks if we already analyzed this URL before
-// It looks in Redis (fast in-memory database) under key: "result:https://example.com"
-func GetCachedResult(url string) (*AnalysisResult, bool) {
-	ctx := context.Background()
-	data, err := redisClient.Get(ctx, "result:"+url).Bytes()
-	if err != nil {
-		return nil, false
+func GetCachedResult(ctx context.Context, url string) (*AnalysisResult, CacheInfo, bool) {
+	entry, ok := ResultCache.Get(ctx, cacheKey(url))
+	if !ok || entry == nil || entry.Result == nil {
+		return nil, CacheInfo{}, false
 	}
-	var res AnalysisResult
-	json.Unmarshal(data, &res)
-	return &res, true
+	return entry.Result, CacheInfo{
+		Hit:        true,
+		StoredAt:   entry.StoredAt,
+		AgeSeconds: int64(time.Since(entry.StoredAt).Seconds()),
+	}, true
 }
 
-// SetCachedResult saves the analysis result in Redis for 1 hour
+// SetCachedResult saves the analysis result for CacheTTL
 // So next time someone asks for the same URL → instant answer!
-func SetCachedResult(url string, res *AnalysisResult) {
-	ctx := context.Background()
-	data, _ := json.Marshal(res)
-	redisClient.Set(ctx, "result:"+url, data, time.Duration(CACHE_TTL)*time.Hour)
-}
\ No newline at end of file
+func SetCachedResult(ctx context.Context, url string, res *AnalysisResult) {
+	ResultCache.Set(ctx, cacheKey(url), &CacheEntry{Result: res, StoredAt: time.Now()}, CacheTTL)
+}
+
+// NoopCache is a Cache that never stores anything (every Get is a miss)
+type NoopCache struct{}
+
+// Get always misses
+func (NoopCache) Get(context.Context, string) (*CacheEntry, bool) { return nil, false }
+
+// Set does nothing
+func (NoopCache) Set(context.Context, string, *CacheEntry, time.Duration) {}
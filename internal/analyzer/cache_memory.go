@@ -0,0 +1,96 @@
+package analyzer
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCache is an in-process cache with:
+// - a size limit (least recently used entries are evicted first)
+// - a per-entry TTL (expired entries are dropped on read)
+// Good for local runs and single-instance deployments without Redis.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List               // front = most recently used
+	items      map[string]*list.Element // key → element in order
+	now        func() time.Time         // swappable clock for tests
+}
+
+// memoryItem is what each list element holds
+type memoryItem struct {
+	key       string
+	entry     *CacheEntry
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates a cache holding at most maxEntries results.
+// maxEntries <= 0 means "no limit".
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// Get returns a live entry and marks it as recently used
+func (c *MemoryCache) Get(_ context.Context, key string) (*CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := el.Value.(*memoryItem)
+
+	// Expired → drop it now instead of waiting for eviction
+	if !c.now().Before(item.expiresAt) {
+		c.removeElement(el)
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	return item.entry, true
+}
+
+// Set stores (or replaces) an entry and evicts the oldest ones if we're full
+func (c *MemoryCache) Set(_ context.Context, key string, entry *CacheEntry, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(ttl)
+
+	// Existing key → update in place
+	if el, ok := c.items[key]; ok {
+		item := el.Value.(*memoryItem)
+		item.entry = entry
+		item.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&memoryItem{key: key, entry: entry, expiresAt: expiresAt})
+
+	// Over the limit → evict from the back (least recently used)
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// Len reports how many entries are stored (expired ones included until touched)
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// removeElement drops an element from both the list and the map (caller holds mu)
+func (c *MemoryCache) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*memoryItem).key)
+}
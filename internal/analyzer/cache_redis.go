@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache stores results in Redis (fast in-memory database),
+// so every app instance behind the load balancer shares one cache.
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache connects lazily – nothing is dialed until the first Get/Set,
+// so a missing Redis only turns every lookup into a miss.
+func NewRedisCache(addr string) *RedisCache {
+	return &RedisCache{
+		client: redis.NewClient(&redis.Options{
+			Addr: addr,
+		}),
+	}
+}
+
+// Get reads and decodes the entry; any Redis or JSON error is a miss
+func (c *RedisCache) Get(ctx context.Context, key string) (*CacheEntry, bool) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, false
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Set encodes the entry as JSON and lets Redis expire it after ttl
+func (c *RedisCache) Set(ctx context.Context, key string, entry *CacheEntry, ttl time.Duration) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	c.client.Set(ctx, key, data, ttl)
+}
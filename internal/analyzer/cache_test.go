@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache_TTLAndLRU(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(2)
+
+	// Fake clock so we don't have to sleep
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	entry := func(title string) *CacheEntry {
+		return &CacheEntry{Result: &AnalysisResult{Title: title}, StoredAt: now}
+	}
+
+	c.Set(ctx, "a", entry("A"), time.Minute)
+	c.Set(ctx, "b", entry("B"), time.Minute)
+
+	// Touch "a" so "b" becomes least recently used
+	if got, ok := c.Get(ctx, "a"); !ok || got.Result.Title != "A" {
+		t.Fatalf("Get(a) = %+v, %v; want A", got, ok)
+	}
+
+	// Adding a third entry evicts "b"
+	c.Set(ctx, "c", entry("C"), time.Minute)
+	if _, ok := c.Get(ctx, "b"); ok {
+		t.Errorf("b should have been evicted")
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len = %d; want 2", c.Len())
+	}
+
+	// Move past the TTL → everything expires
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.Get(ctx, "a"); ok {
+		t.Errorf("a should have expired")
+	}
+}
+
+func TestNoopCache_AlwaysMisses(t *testing.T) {
+	var c Cache = NoopCache{}
+	c.Set(context.Background(), "k", &CacheEntry{Result: &AnalysisResult{}}, time.Hour)
+	if _, ok := c.Get(context.Background(), "k"); ok {
+		t.Fatal("NoopCache returned a hit")
+	}
+}
+
+func TestAnalyzeURLCached(t *testing.T) {
+	oldCache := ResultCache
+	defer func() { ResultCache = oldCache }()
+	ResultCache = NewMemoryCache(10)
+
+	// Count how often the page is really fetched
+	var fetches int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		_, _ = w.Write([]byte(`<!doctype html><title>Cached</title>`))
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+
+	// 1st call: miss → fetch
+	res, info, err := AnalyzeURLCached(ctx, ts.URL, false, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Hit || res.Title != "Cached" {
+		t.Fatalf("first call: hit=%v title=%q; want miss + title", info.Hit, res.Title)
+	}
+
+	// 2nd call: hit → no fetch
+	_, info, err = AnalyzeURLCached(ctx, ts.URL, false, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Hit {
+		t.Errorf("second call should be a cache hit")
+	}
+
+	// 3rd call with refresh: fetch again even though it's cached
+	_, info, err = AnalyzeURLCached(ctx, ts.URL, true, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Hit {
+		t.Errorf("refresh call should not be a cache hit")
+	}
+
+	if got := atomic.LoadInt32(&fetches); got != 2 {
+		t.Errorf("page fetched %d times; want 2", got)
+	}
+}
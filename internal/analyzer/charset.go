@@ -0,0 +1,160 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// Values of Encoding.Source: where the charset we used came from
+const (
+	EncodingFromBOM     = "bom"     // Byte order mark at the start of the body
+	EncodingFromHeader  = "header"  // charset= in the Content-Type header
+	EncodingFromMeta    = "meta"    // <meta charset> or <meta http-equiv="Content-Type">
+	EncodingFromSniff   = "sniffed" // Nothing declared, but the bytes are valid UTF-8
+	EncodingFromDefault = "default" // Nothing declared: windows-1252, like browsers
+)
+
+// Encoding is the character encoding of a fetched page: what each source
+// declared, which one won, and where they disagree
+type Encoding struct {
+	Charset string   `json:"charset"`          // Canonical name of the encoding used, e.g. "utf-8", "shift_jis"
+	Source  string   `json:"source"`           // bom / header / meta / sniffed / default
+	BOM     string   `json:"bom,omitempty"`    // Encoding named by the byte order mark
+	Header  string   `json:"header,omitempty"` // charset from Content-Type, as written
+	Meta    string   `json:"meta,omitempty"`   // charset from <meta>, as written
+	Issues  []string `json:"issues,omitempty"` // Mismatches, unknown labels, invalid UTF-8...
+}
+
+// boms are the byte order marks browsers recognise
+var boms = []struct {
+	bom     []byte
+	charset string
+}{
+	{[]byte{0xEF, 0xBB, 0xBF}, "utf-8"},
+	{[]byte{0xFE, 0xFF}, "utf-16be"},
+	{[]byte{0xFF, 0xFE}, "utf-16le"},
+}
+
+// metaPrescanBytes is how far into the body browsers look for a <meta> charset
+const metaPrescanBytes = 1024
+
+// decodeHTML works out body's encoding and converts it to UTF-8 for html.Parse.
+// Precedence follows the HTML spec: BOM, then Content-Type, then <meta>, then a guess.
+func decodeHTML(body []byte, contentType string) ([]byte, Encoding) {
+	var enc Encoding
+
+	// === Collect what every source says ===
+	bomCharset, bomLen := "", 0
+	for _, b := range boms {
+		if bytes.HasPrefix(body, b.bom) {
+			bomCharset, bomLen = b.charset, len(b.bom)
+			break
+		}
+	}
+	enc.BOM = bomCharset
+
+	headerCharset := ""
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		enc.Header = params["charset"]
+		if _, name := charset.Lookup(enc.Header); name != "" {
+			headerCharset = name
+		} else {
+			enc.Issues = append(enc.Issues, fmt.Sprintf("unknown charset %q in Content-Type", enc.Header))
+		}
+	}
+
+	metaCharset := ""
+	if enc.Meta = metaCharsetLabel(body[bomLen:]); enc.Meta != "" {
+		if _, name := charset.Lookup(enc.Meta); name != "" {
+			metaCharset = name
+		} else {
+			enc.Issues = append(enc.Issues, fmt.Sprintf("unknown charset %q in <meta>", enc.Meta))
+		}
+	}
+
+	// === Pick the winner ===
+	switch {
+	case bomCharset != "":
+		enc.Charset, enc.Source = bomCharset, EncodingFromBOM
+	case headerCharset != "":
+		enc.Charset, enc.Source = headerCharset, EncodingFromHeader
+	case metaCharset != "":
+		enc.Charset, enc.Source = metaCharset, EncodingFromMeta
+	case utf8.Valid(body):
+		enc.Charset, enc.Source = "utf-8", EncodingFromSniff
+	default:
+		enc.Charset, enc.Source = "windows-1252", EncodingFromDefault
+	}
+
+	// === Flag disagreements between declared sources ===
+	declared := []struct{ what, name string }{
+		{"byte order mark", bomCharset}, {"Content-Type", headerCharset}, {"<meta>", metaCharset},
+	}
+	for i, a := range declared {
+		for _, b := range declared[i+1:] {
+			if a.name != "" && b.name != "" && a.name != b.name {
+				enc.Issues = append(enc.Issues, fmt.Sprintf("%s says %s but %s says %s", a.what, a.name, b.what, b.name))
+			}
+		}
+	}
+
+	// === Convert to UTF-8 ===
+	body = body[bomLen:]
+	if enc.Charset == "utf-8" {
+		if !utf8.Valid(body) {
+			// Browsers show bad bytes as "�"; so do we
+			enc.Issues = append(enc.Issues, "declared as utf-8 but the content is not valid UTF-8")
+			body = bytes.ToValidUTF8(body, []byte("\uFFFD"))
+		}
+		return body, enc
+	}
+	e, _ := charset.Lookup(enc.Charset)
+	decoded, err := e.NewDecoder().Bytes(body)
+	if err != nil {
+		enc.Issues = append(enc.Issues, fmt.Sprintf("could not decode as %s: %v", enc.Charset, err))
+		return body, enc
+	}
+	return decoded, enc
+}
+
+// metaCharsetLabel finds the charset declared in the first metaPrescanBytes of body:
+// <meta charset="..."> or <meta http-equiv="Content-Type" content="text/html; charset=...">
+func metaCharsetLabel(body []byte) string {
+	if len(body) > metaPrescanBytes {
+		body = body[:metaPrescanBytes]
+	}
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := z.Token()
+			if token.Data != "meta" {
+				continue
+			}
+			var httpEquiv, content string
+			for _, attr := range token.Attr {
+				switch strings.ToLower(attr.Key) {
+				case "charset":
+					return strings.TrimSpace(attr.Val)
+				case "http-equiv":
+					httpEquiv = strings.ToLower(strings.TrimSpace(attr.Val))
+				case "content":
+					content = attr.Val
+				}
+			}
+			if httpEquiv == "content-type" {
+				if _, params, err := mime.ParseMediaType(content); err == nil && params["charset"] != "" {
+					return params["charset"]
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeHTML(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		charset     string
+		source      string
+		title       string
+		issues      []string
+	}{
+		{"windows-1251 from header",
+			"<title>\xcf\xf0\xe8\xe2\xe5\xf2</title>", "text/html; charset=windows-1251",
+			"windows-1251", EncodingFromHeader, "Привет", nil},
+		{"shift_jis from meta charset",
+			`<meta charset="Shift_JIS"><title>` + "\x93\xfa\x96\x7b" + `</title>`, "text/html",
+			"shift_jis", EncodingFromMeta, "日本", nil},
+		{"iso-8859-1 from http-equiv (read as windows-1252, like browsers)",
+			`<meta http-equiv="Content-Type" content="text/html; charset=ISO-8859-1"><title>caf` + "\xe9" + `</title>`, "",
+			"windows-1252", EncodingFromMeta, "café", nil},
+		{"header wins over meta, mismatch flagged",
+			`<meta charset="utf-8"><title>` + "\xcf\xf0\xe8\xe2\xe5\xf2" + `</title>`, "text/html; charset=windows-1251",
+			"windows-1251", EncodingFromHeader, "Привет", []string{"Content-Type says windows-1251 but <meta> says utf-8"}},
+		{"bom wins and is stripped",
+			"\xef\xbb\xbf<title>héllo</title>", "text/html; charset=iso-8859-1",
+			"utf-8", EncodingFromBOM, "héllo", []string{"byte order mark says utf-8 but Content-Type says windows-1252"}},
+		{"undeclared utf-8 is sniffed",
+			"<title>héllo</title>", "text/html",
+			"utf-8", EncodingFromSniff, "héllo", nil},
+		{"undeclared legacy bytes default to windows-1252",
+			"<title>caf\xe9</title>", "",
+			"windows-1252", EncodingFromDefault, "café", nil},
+		{"unknown label and invalid utf-8",
+			"<title>caf\xe9</title>", "text/html; charset=klingon; x=1",
+			"windows-1252", EncodingFromDefault, "café", []string{`unknown charset "klingon" in Content-Type`}},
+		{"declared utf-8 but isn't",
+			"<title>caf\xe9</title>", "text/html; charset=utf-8",
+			"utf-8", EncodingFromHeader, "caf�", []string{"declared as utf-8 but the content is not valid UTF-8"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, enc := decodeHTML([]byte(tt.body), tt.contentType)
+			if enc.Charset != tt.charset || enc.Source != tt.source {
+				t.Errorf("Charset = %q from %q; want %q from %q", enc.Charset, enc.Source, tt.charset, tt.source)
+			}
+			if !reflect.DeepEqual(enc.Issues, tt.issues) {
+				t.Errorf("Issues = %q; want %q", enc.Issues, tt.issues)
+			}
+			result, err := AnalyzePage(bytes.NewReader(body), "")
+			if err != nil {
+				t.Fatalf("AnalyzePage: %v", err)
+			}
+			if result.Title != tt.title {
+				t.Errorf("Title = %q; want %q", result.Title, tt.title)
+			}
+		})
+	}
+}
+
+func TestAnalyzeURL_Encoding(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=windows-1251")
+		_, _ = w.Write([]byte("<title>\xcf\xf0\xe8\xe2\xe5\xf2</title><h1>\xcc\xe8\xf0</h1>"))
+	}))
+	defer ts.Close()
+
+	result, err := AnalyzeURL(context.Background(), ts.URL, Options{})
+	if err != nil {
+		t.Fatalf("AnalyzeURL: %v", err)
+	}
+	if result.Title != "Привет" {
+		t.Errorf("Title = %q; want Привет", result.Title)
+	}
+	if result.Encoding == nil || result.Encoding.Charset != "windows-1251" || result.Encoding.Header != "windows-1251" {
+		t.Errorf("Encoding = %+v; want windows-1251 from the header", result.Encoding)
+	}
+}
@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Media types we can analyze
+const (
+	MediaTypeHTML  = "text/html"
+	MediaTypeXHTML = "application/xhtml+xml"
+)
+
+// xhtmlNamespace marks an XML document as XHTML (<html xmlns="...">)
+const xhtmlNamespace = "http://www.w3.org/1999/xhtml"
+
+// UnsupportedContentError is returned for responses that aren't HTML (PDFs, images, JSON...)
+type UnsupportedContentError struct {
+	Declared string // Media type from the Content-Type header ("" if none)
+	Detected string // Media type sniffed from the body
+}
+
+func (e *UnsupportedContentError) Error() string {
+	if e.Declared == "" || e.Declared == e.Detected {
+		return fmt.Sprintf("content is %s, not HTML", e.Detected)
+	}
+	return fmt.Sprintf("content is %s (served as %s), not HTML", e.Detected, e.Declared)
+}
+
+// genericMediaTypes say nothing about the content, so we trust the sniffed type instead
+var genericMediaTypes = map[string]bool{
+	"":                         true,
+	"application/octet-stream": true,
+	"application/unknown":      true,
+	"unknown/unknown":          true,
+}
+
+// checkContentType decides whether a response can be analyzed, from its
+// Content-Type header and the body itself (http.DetectContentType sniffing):
+// - text/html → HTML, unless the body is plainly binary (an image served as text/html)
+// - application/xhtml+xml, or XML whose root is an XHTML <html> → XHTML
+// - no or a generic Content-Type → whatever the body looks like
+// - anything else → *UnsupportedContentError
+// It returns the media type the page is analyzed as.
+func checkContentType(contentType string, body []byte) (string, error) {
+	declared, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		declared = "" // Unparseable header: treat it like a missing one
+	}
+	detected, _, _ := mime.ParseMediaType(http.DetectContentType(body))
+
+	switch {
+	case declared == MediaTypeHTML || declared == MediaTypeXHTML:
+		if isBinaryMediaType(detected) {
+			return "", &UnsupportedContentError{Declared: declared, Detected: detected}
+		}
+		return declared, nil
+
+	case isXMLMediaType(declared) || genericMediaTypes[declared] && isXMLMediaType(detected):
+		if isXHTML(body) {
+			return MediaTypeXHTML, nil
+		}
+
+	case genericMediaTypes[declared] && detected == MediaTypeHTML:
+		return MediaTypeHTML, nil
+	}
+
+	if genericMediaTypes[declared] {
+		declared = ""
+	}
+	return "", &UnsupportedContentError{Declared: declared, Detected: detected}
+}
+
+// isBinaryMediaType is true for sniffed types that can't be markup
+func isBinaryMediaType(t string) bool {
+	switch {
+	case strings.HasPrefix(t, "image/"), strings.HasPrefix(t, "audio/"), strings.HasPrefix(t, "video/"),
+		strings.HasPrefix(t, "font/"):
+		return true
+	}
+	switch t {
+	case "application/pdf", "application/zip", "application/x-gzip", "application/x-rar-compressed",
+		"application/wasm", "application/vnd.ms-fontobject", "application/postscript":
+		return true
+	}
+	return false
+}
+
+// isXMLMediaType matches text/xml, application/xml and any */*+xml
+func isXMLMediaType(t string) bool {
+	return t == "text/xml" || t == "application/xml" || strings.HasSuffix(t, "+xml")
+}
+
+// isXHTML reports whether an XML document's root looks like <html xmlns="http://www.w3.org/1999/xhtml">.
+// Checking the start of the body is enough: the root comes after at most
+// an XML declaration, a doctype and some comments.
+func isXHTML(body []byte) bool {
+	head := bytes.ToLower(body[:min(len(body), metaPrescanBytes)])
+	return bytes.Contains(head, []byte("<html")) && bytes.Contains(head, []byte(xhtmlNamespace))
+}
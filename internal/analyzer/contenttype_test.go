@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckContentType(t *testing.T) {
+	const page = `<!DOCTYPE html><html><head><title>x</title></head></html>`
+	const xhtml = `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml"><head><title>x</title></head></html>`
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+	tests := []struct {
+		name, contentType, body string
+		want                    string // media type, or "" for an error
+		detected                string // UnsupportedContentError.Detected when want is ""
+	}{
+		{"html", "text/html; charset=utf-8", page, MediaTypeHTML, ""},
+		{"xhtml", "application/xhtml+xml", xhtml, MediaTypeXHTML, ""},
+		{"xhtml served as xml", "application/xml", xhtml, MediaTypeXHTML, ""},
+		{"no content type, html body", "", page, MediaTypeHTML, ""},
+		{"octet-stream, html body", "application/octet-stream", page, MediaTypeHTML, ""},
+		{"pdf", "application/pdf", "%PDF-1.7\n...", "", "application/pdf"},
+		{"json", "application/json", `{"a": 1}`, "", "text/plain"},
+		{"plain xml", "text/xml", `<?xml version="1.0"?><rss></rss>`, "", "text/xml"},
+		{"image served as html", "text/html", png, "", "image/png"},
+		{"image without content type", "", png, "", "image/png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checkContentType(tt.contentType, []byte(tt.body))
+			var unsupported *UnsupportedContentError
+			switch {
+			case tt.want != "" && (err != nil || got != tt.want):
+				t.Errorf("got %q, %v; want %q", got, err, tt.want)
+			case tt.want == "" && !errors.As(err, &unsupported):
+				t.Errorf("got %q, %v; want *UnsupportedContentError", got, err)
+			case tt.want == "" && unsupported.Detected != tt.detected:
+				t.Errorf("Detected = %q; want %q", unsupported.Detected, tt.detected)
+			}
+		})
+	}
+}
+
+func TestAnalyzeURL_RejectsNonHTML(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-1.4\n%..."))
+	}))
+	defer ts.Close()
+
+	_, err := AnalyzeURL(context.Background(), ts.URL+"/report.pdf", Options{})
+	var aerr *AnalysisError
+	if !errors.As(err, &aerr) || aerr.Code != ErrCodeUnsupportedType || aerr.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("err = %v; want 422 %s", err, ErrCodeUnsupportedType)
+	}
+	if aerr.Details["detected_type"] != "application/pdf" || aerr.Details["content_type"] != "application/pdf" {
+		t.Errorf("Details = %v; want content_type and detected_type application/pdf", aerr.Details)
+	}
+}
@@ -0,0 +1,373 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Crawl limits. A request may ask for less, never for more.
+// (set from CRAWL_MAX_DEPTH, CRAWL_MAX_PAGES and CRAWL_CONCURRENCY in main.go)
+var (
+	CrawlMaxDepth    = 3   // Link hops from the seed page (0 = seed only)
+	CrawlMaxPages    = 100 // Pages analyzed per crawl, seed included
+	CrawlConcurrency = 4   // Pages analyzed at once per crawl; every request also takes a shared pool token
+)
+
+// CrawlOptions controls one crawl. Zero values fall back to the limits above.
+type CrawlOptions struct {
+	MaxDepth int              // Link hops from the seed (<0 = CrawlMaxDepth)
+	MaxPages int              // Max pages analyzed (<=0 = CrawlMaxPages)
+	Include  []*regexp.Regexp // If set, a discovered URL must match one of these
+	Exclude  []*regexp.Regexp // A discovered URL matching any of these is skipped
+	Refresh  bool             // Bypass the result cache
+}
+
+// CrawlPage is one analyzed page: Result or Error, never both
+type CrawlPage struct {
+	URL        string          `json:"url"`
+	Depth      int             `json:"depth"`       // Link hops from the seed
+	LinkedFrom []string        `json:"linked_from"` // Crawled pages that link here (empty for the seed)
+	Cache      *CacheInfo      `json:"cache,omitempty"`
+	Result     *AnalysisResult `json:"result,omitempty"`
+	Error      *AnalysisError  `json:"error,omitempty"`
+}
+
+// BrokenLinkSources is one broken URL and every crawled page that links to it
+type BrokenLinkSources struct {
+	URL     string   `json:"url"`
+	Status  int      `json:"status,omitempty"` // Last HTTP status (0 = network error)
+	Error   string   `json:"error,omitempty"`
+	FoundOn []string `json:"found_on"`
+}
+
+// DuplicateTitle is a <title> shared by more than one page
+type DuplicateTitle struct {
+	Title string   `json:"title"`
+	URLs  []string `json:"urls"`
+}
+
+// CrawlSummary adds up the whole crawl
+type CrawlSummary struct {
+	Pages       int  `json:"pages"`        // Pages analyzed (seed included)
+	Failed      int  `json:"failed"`       // Pages that could not be analyzed
+	MaxDepth    int  `json:"max_depth"`    // Deepest level reached
+	BrokenLinks int  `json:"broken_links"` // Distinct broken URLs
+	Truncated   bool `json:"truncated"`    // Stopped early because of MaxPages
+}
+
+// CrawlReport is the response of POST /api/v1/crawl
+type CrawlReport struct {
+	Seed            string              `json:"seed"`
+	Summary         CrawlSummary        `json:"summary"`
+	Pages           []CrawlPage         `json:"pages"`            // Breadth-first order
+	BrokenLinks     []BrokenLinkSources `json:"broken_links"`     // Sorted by URL
+	OrphanPages     []string            `json:"orphan_pages"`     // Linked from at most one other page
+	DuplicateTitles []DuplicateTitle    `json:"duplicate_titles"` // Sorted by title
+}
+
+// apiCrawlRequest is the JSON body of POST /api/v1/crawl
+type apiCrawlRequest struct {
+	URL      string   `json:"url"`
+	MaxDepth *int     `json:"max_depth"` // Pointer: 0 (seed only) is a valid choice
+	MaxPages int      `json:"max_pages"`
+	Include  []string `json:"include"` // Regular expressions, matched against the full URL
+	Exclude  []string `json:"exclude"`
+	Refresh  bool     `json:"refresh"`
+}
+
+// Crawl analyzes the seed page, then follows its internal links breadth-first:
+// every page of depth N is analyzed (CrawlConcurrency at a time, and every
+// fetch within the shared MaxWorkers limit, see workerpool.go) before any
+// page of depth N+1. Pages that redirect to another host are recorded but
+// not expanded. Per-page failures are recorded in the page; Crawl only
+// fails when the seed URL itself is invalid.
+func Crawl(ctx context.Context, seed string, opts CrawlOptions) (*CrawlReport, error) {
+	if err := ValidateURL(seed); err != nil {
+		return nil, err
+	}
+	opts = opts.withLimits()
+
+	pool := NewWorkerPool(CrawlConcurrency) // Pages in flight for this crawl; their requests share workerPool
+	seen := map[string]bool{crawlKey(seed): true}
+	linkedFrom := make(map[string]map[string]bool) // target page → pages linking to it
+	report := &CrawlReport{Seed: seed}
+	siteHost := "" // Where the seed landed after its redirects; only pages there are expanded
+
+	// === BFS: one level at a time ===
+	level := []string{seed}
+	for depth := 0; len(level) > 0 && ctx.Err() == nil; depth++ {
+		report.Summary.MaxDepth = depth
+		var next []string
+
+		for _, item := range analyzeURLs(ctx, level, opts.Refresh, pool) {
+			report.Pages = append(report.Pages, CrawlPage{
+				URL:    item.URL,
+				Depth:  depth,
+				Cache:  item.Cache,
+				Result: item.Result,
+				Error:  item.Error,
+			})
+			if item.Result == nil {
+				continue
+			}
+			// A link that redirected off the site: its "internal" links belong to another host
+			host := pageHost(item)
+			if depth == 0 {
+				siteHost = host
+			}
+			if host != siteHost {
+				continue
+			}
+
+			// Queue internal links we haven't seen yet
+			from := crawlKey(item.URL)
+			for _, link := range item.Result.Links.Items {
+				if link.Kind != LinkInternal {
+					continue
+				}
+				target := crawlKey(link.URL)
+				if target != from {
+					if linkedFrom[target] == nil {
+						linkedFrom[target] = make(map[string]bool)
+					}
+					linkedFrom[target][from] = true
+				}
+
+				if seen[target] || depth >= opts.MaxDepth || !opts.allows(target) {
+					continue
+				}
+				if len(seen) >= opts.MaxPages {
+					report.Summary.Truncated = true
+					continue
+				}
+				seen[target] = true
+				next = append(next, target)
+			}
+		}
+		level = next
+	}
+
+	// === Site-level views ===
+	for i := range report.Pages {
+		report.Pages[i].LinkedFrom = sortedKeys(linkedFrom[crawlKey(report.Pages[i].URL)])
+	}
+	report.BrokenLinks = brokenLinkSources(report.Pages)
+	report.OrphanPages = orphanPages(report.Pages)
+	report.DuplicateTitles = duplicateTitles(report.Pages)
+
+	report.Summary.Pages = len(report.Pages)
+	report.Summary.BrokenLinks = len(report.BrokenLinks)
+	for _, p := range report.Pages {
+		if p.Error != nil {
+			report.Summary.Failed++
+		}
+	}
+	return report, nil
+}
+
+// pageHost is the lowercased host a crawled page was finally served from
+func pageHost(item BatchItem) string {
+	final := item.URL
+	if item.Result.FinalURL != "" {
+		final = item.Result.FinalURL
+	}
+	u, err := url.Parse(final)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// withLimits fills in defaults and clamps the request to the server limits
+func (o CrawlOptions) withLimits() CrawlOptions {
+	if o.MaxDepth < 0 || o.MaxDepth > CrawlMaxDepth {
+		o.MaxDepth = CrawlMaxDepth
+	}
+	if o.MaxPages <= 0 || o.MaxPages > CrawlMaxPages {
+		o.MaxPages = CrawlMaxPages
+	}
+	return o
+}
+
+// allows applies the include/exclude patterns to a discovered URL
+func (o CrawlOptions) allows(rawURL string) bool {
+	for _, re := range o.Exclude {
+		if re.MatchString(rawURL) {
+			return false
+		}
+	}
+	if len(o.Include) == 0 {
+		return true
+	}
+	for _, re := range o.Include {
+		if re.MatchString(rawURL) {
+			return true
+		}
+	}
+	return false
+}
+
+// crawlKey normalizes a URL so the same page isn't crawled twice:
+// the #fragment is dropped and an empty path becomes "/"
+func crawlKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String()
+}
+
+// compilePatterns turns the request's include/exclude strings into regexps
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, newAnalysisError(http.StatusBadRequest, ErrCodeBadRequest, "Invalid pattern %q: %v", p, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// brokenLinkSources groups broken links by URL, listing the pages they were found on
+func brokenLinkSources(pages []CrawlPage) []BrokenLinkSources {
+	byURL := make(map[string]*BrokenLinkSources)
+	foundOn := make(map[string]map[string]bool)
+	for _, p := range pages {
+		if p.Result == nil {
+			continue
+		}
+		for _, link := range p.Result.Links.Items {
+			if link.Health != LinkBroken {
+				continue
+			}
+			if byURL[link.URL] == nil {
+				byURL[link.URL] = &BrokenLinkSources{URL: link.URL, Status: link.Status, Error: link.Error}
+				foundOn[link.URL] = make(map[string]bool)
+			}
+			foundOn[link.URL][p.URL] = true
+		}
+	}
+
+	out := make([]BrokenLinkSources, 0, len(byURL))
+	for u, b := range byURL {
+		b.FoundOn = sortedKeys(foundOn[u])
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].URL < out[j].URL })
+	return out
+}
+
+// orphanPages lists crawled pages (other than the seed) that at most one
+// other page links to. A crawler can only reach pages that are linked from
+// somewhere, so true orphans never show up – these are the next best thing:
+// pages that disappear from the site as soon as a single link is removed.
+func orphanPages(pages []CrawlPage) []string {
+	out := []string{}
+	for _, p := range pages {
+		if p.Depth > 0 && p.Result != nil && len(p.LinkedFrom) <= 1 {
+			out = append(out, p.URL)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// duplicateTitles finds non-empty titles used by more than one page
+func duplicateTitles(pages []CrawlPage) []DuplicateTitle {
+	byTitle := make(map[string][]string)
+	for _, p := range pages {
+		if p.Result == nil || p.Result.Title == "" {
+			continue
+		}
+		byTitle[p.Result.Title] = append(byTitle[p.Result.Title], p.URL)
+	}
+
+	out := []DuplicateTitle{}
+	for title, urls := range byTitle {
+		if len(urls) > 1 {
+			sort.Strings(urls)
+			out = append(out, DuplicateTitle{Title: title, URLs: urls})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Title < out[j].Title })
+	return out
+}
+
+// sortedKeys returns the keys of a string set in order (never nil, so JSON gets [])
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CrawlHandler serves POST /api/v1/crawl:
+// {"url": "https://example.com", "max_depth": 2, "max_pages": 50,
+//
+//	"include": ["^https://example\\.com/docs/"], "exclude": ["\\.pdf$"]}
+func CrawlHandler(log *logrus.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// === STEP 1: Only allow POST ===
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeAPIError(w, newAnalysisError(http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "Method not allowed"))
+			return
+		}
+
+		// === STEP 2: Decode the request and build the options ===
+		var req apiCrawlRequest
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		opts := CrawlOptions{MaxDepth: -1, MaxPages: req.MaxPages, Refresh: req.Refresh}
+		if req.MaxDepth != nil {
+			opts.MaxDepth = *req.MaxDepth
+		}
+		var err error
+		if opts.Include, err = compilePatterns(req.Include); err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		if opts.Exclude, err = compilePatterns(req.Exclude); err != nil {
+			writeAPIError(w, err)
+			return
+		}
+
+		log.WithFields(logrus.Fields{
+			"url":       req.URL,
+			"max_depth": opts.MaxDepth,
+			"max_pages": opts.MaxPages,
+		}).Info("Starting crawl")
+
+		// === STEP 3: Crawl and report ===
+		report, err := Crawl(r.Context(), req.URL, opts)
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+
+		log.WithFields(logrus.Fields{
+			"url":    req.URL,
+			"pages":  report.Summary.Pages,
+			"failed": report.Summary.Failed,
+		}).Info("Crawl finished")
+
+		writeJSON(w, http.StatusOK, report)
+	}
+}
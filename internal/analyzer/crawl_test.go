@@ -0,0 +1,194 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestCrawlHandler(t *testing.T) {
+	// Link checks: "/missing" is broken, everything else OK
+	oldClient := httpClient
+	defer func() { httpClient = oldClient }()
+	httpClient = &http.Client{
+		Transport: mockTransport(func(req *http.Request) *http.Response {
+			status := http.StatusOK
+			if req.URL.Path == "/missing" {
+				status = http.StatusNotFound
+			}
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(strings.NewReader("")),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	// A tiny site:  /  → /a, /b, /missing, external
+	//               /a → /, /b, /a/deep      /b → /      /a/deep → /private/x
+	site := map[string]string{
+		"/":          `<title>Home</title><a href="/a">a</a><a href="/b#top">b</a><a href="/missing">gone</a><a href="https://ext.example/">ext</a>`,
+		"/a":         `<title>Dup</title><a href="/">home</a><a href="/b">b</a><a href="/a/deep">deep</a>`,
+		"/b":         `<title>Dup</title><a href="/">home</a>`,
+		"/a/deep":    `<title>Deep</title><a href="/private/x">secret</a>`,
+		"/private/x": `<title>Private</title>`,
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := site[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(page))
+	}))
+	defer ts.Close()
+
+	h := CrawlHandler(logrus.New())
+	post := func(body string) (*httptest.ResponseRecorder, CrawlReport) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/crawl", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		var report CrawlReport
+		_ = json.Unmarshal(rr.Body.Bytes(), &report)
+		return rr, report
+	}
+
+	t.Run("full crawl with exclude pattern", func(t *testing.T) {
+		rr, report := post(`{"url":"` + ts.URL + `/","exclude":["/private/"],"refresh":true}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d; want 200; body: %s", rr.Code, rr.Body.String())
+		}
+
+		wantSummary := CrawlSummary{Pages: 5, Failed: 1, MaxDepth: 2, BrokenLinks: 1}
+		if report.Summary != wantSummary {
+			t.Errorf("Summary = %+v; want %+v", report.Summary, wantSummary)
+		}
+
+		// Breadth-first: the seed, then depth 1 in discovery order, then depth 2
+		var order []string
+		for _, p := range report.Pages {
+			order = append(order, strings.TrimPrefix(p.URL, ts.URL))
+		}
+		if want := []string{"/", "/a", "/b", "/missing", "/a/deep"}; !reflect.DeepEqual(order, want) {
+			t.Errorf("page order = %v; want %v", order, want)
+		}
+
+		if len(report.BrokenLinks) != 1 || report.BrokenLinks[0].URL != ts.URL+"/missing" ||
+			!reflect.DeepEqual(report.BrokenLinks[0].FoundOn, []string{ts.URL + "/"}) {
+			t.Errorf("BrokenLinks = %+v; want /missing found on /", report.BrokenLinks)
+		}
+		if want := []string{ts.URL + "/", ts.URL + "/a"}; !reflect.DeepEqual(report.Pages[2].LinkedFrom, want) {
+			t.Errorf("/b LinkedFrom = %v; want %v", report.Pages[2].LinkedFrom, want)
+		}
+		if want := []string{ts.URL + "/a", ts.URL + "/a/deep"}; !reflect.DeepEqual(report.OrphanPages, want) {
+			t.Errorf("OrphanPages = %v; want %v", report.OrphanPages, want)
+		}
+		wantDup := []DuplicateTitle{{Title: "Dup", URLs: []string{ts.URL + "/a", ts.URL + "/b"}}}
+		if !reflect.DeepEqual(report.DuplicateTitles, wantDup) {
+			t.Errorf("DuplicateTitles = %+v; want %+v", report.DuplicateTitles, wantDup)
+		}
+	})
+
+	t.Run("depth and page limits", func(t *testing.T) {
+		_, report := post(`{"url":"` + ts.URL + `/","max_depth":0,"refresh":true}`)
+		if report.Summary.Pages != 1 {
+			t.Errorf("max_depth 0: pages = %d; want 1 (seed only)", report.Summary.Pages)
+		}
+
+		_, report = post(`{"url":"` + ts.URL + `/","max_pages":2,"refresh":true}`)
+		if report.Summary.Pages != 2 || !report.Summary.Truncated {
+			t.Errorf("max_pages 2: summary = %+v; want 2 pages, truncated", report.Summary)
+		}
+	})
+
+	t.Run("include pattern", func(t *testing.T) {
+		_, report := post(`{"url":"` + ts.URL + `/","include":["/a"],"refresh":true}`)
+		if report.Summary.Pages != 3 { // seed + /a + /a/deep
+			t.Errorf("pages = %d; want 3", report.Summary.Pages)
+		}
+	})
+
+	t.Run("bad input", func(t *testing.T) {
+		for _, body := range []string{`{"url":"nope"}`, `{"url":"` + ts.URL + `","include":["("]}`} {
+			rr, _ := post(body)
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("%s: status = %d; want 400", body, rr.Code)
+			}
+		}
+	})
+}
+
+func TestCrawl_StaysOnSeedHostAcrossRedirects(t *testing.T) {
+	// /out on the seed redirects to another host, whose links must not be crawled
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<title>Other</title><a href="/elsewhere">elsewhere</a>`))
+	}))
+	defer other.Close()
+	seed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/out" {
+			http.Redirect(w, r, other.URL+"/landing", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte(`<title>Seed</title><a href="/out">out</a>`))
+	}))
+	defer seed.Close()
+
+	report, err := Crawl(context.Background(), seed.URL+"/", CrawlOptions{MaxDepth: 2, Refresh: true})
+	if err != nil {
+		t.Fatalf("Crawl: %v", err)
+	}
+	for _, p := range report.Pages {
+		if strings.HasPrefix(p.URL, other.URL) {
+			t.Errorf("crawled %s on the redirect target's host", p.URL)
+		}
+	}
+}
+
+func TestCrawl_FetchesTakeSharedPoolTokens(t *testing.T) {
+	// Swap in a shared pool of one token, and hold that token ourselves
+	initWorkerPool()
+	old := workerPool
+	workerPool = NewWorkerPool(1)
+	defer func() { workerPool = old }()
+	Acquire()
+
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`<title>Seed</title>`))
+	}))
+	defer ts.Close()
+
+	done := make(chan *CrawlReport)
+	go func() {
+		report, _ := Crawl(context.Background(), ts.URL+"/", CrawlOptions{MaxDepth: 0, Refresh: true})
+		done <- report
+	}()
+
+	// The crawl can't fetch anything while the only token is taken
+	time.Sleep(50 * time.Millisecond)
+	if n := requests.Load(); n != 0 {
+		t.Fatalf("%d requests sent without a shared pool token", n)
+	}
+	Release()
+
+	select {
+	case report := <-done:
+		if len(report.Pages) != 1 || report.Pages[0].Error != nil {
+			t.Errorf("pages = %+v; want the seed analyzed once the token is free", report.Pages)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("crawl did not finish after the token was released")
+	}
+}
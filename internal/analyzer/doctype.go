@@ -0,0 +1,203 @@
+package analyzer
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Doctype is what the page's <!DOCTYPE> says about its HTML version,
+// and which rendering mode a browser will pick because of it
+type Doctype struct {
+	Version  string `json:"version"`             // "HTML5", "HTML 4.01", "XHTML 1.0", ... or "Unknown"
+	Variant  string `json:"variant,omitempty"`   // "Strict", "Transitional" or "Frameset" (older versions only)
+	PublicID string `json:"public_id,omitempty"` // e.g. "-//W3C//DTD HTML 4.01//EN"
+	SystemID string `json:"system_id,omitempty"` // e.g. "http://www.w3.org/TR/html4/strict.dtd"
+	Mode     string `json:"mode"`                // no-quirks / limited-quirks / quirks
+}
+
+// Values of Doctype.Mode (see https://quirks.spec.whatwg.org/)
+const (
+	ModeNoQuirks      = "no-quirks"      // Standards mode
+	ModeLimitedQuirks = "limited-quirks" // "Almost standards": only table cell heights behave the old way
+	ModeQuirks        = "quirks"         // Browsers emulate 1990s bugs
+)
+
+// String is the version plus variant, e.g. "HTML 4.01 Strict" (this is AnalysisResult.HTMLVersion)
+func (d Doctype) String() string {
+	if d.Variant == "" {
+		return d.Version
+	}
+	return d.Version + " " + d.Variant
+}
+
+// knownDoctype maps the start of a public identifier (lowercase) to a version
+type knownDoctype struct {
+	prefix, version, variant string
+}
+
+// knownPublicIDs are the standard public identifiers. Each prefix ends in "//"
+// where needed so "html 4.0//" doesn't match "html 4.01//".
+var knownPublicIDs = []knownDoctype{
+	{"-//w3c//dtd html 4.01//", "HTML 4.01", "Strict"},
+	{"-//w3c//dtd html 4.01 transitional//", "HTML 4.01", "Transitional"},
+	{"-//w3c//dtd html 4.01 frameset//", "HTML 4.01", "Frameset"},
+	{"-//w3c//dtd html 4.0//", "HTML 4.0", "Strict"},
+	{"-//w3c//dtd html 4.0 transitional//", "HTML 4.0", "Transitional"},
+	{"-//w3c//dtd html 4.0 frameset//", "HTML 4.0", "Frameset"},
+	{"-//w3c//dtd xhtml 1.0 strict//", "XHTML 1.0", "Strict"},
+	{"-//w3c//dtd xhtml 1.0 transitional//", "XHTML 1.0", "Transitional"},
+	{"-//w3c//dtd xhtml 1.0 frameset//", "XHTML 1.0", "Frameset"},
+	{"-//w3c//dtd xhtml 1.1//", "XHTML 1.1", ""},
+	{"-//w3c//dtd xhtml basic 1.0//", "XHTML Basic 1.0", ""},
+	{"-//w3c//dtd xhtml basic 1.1//", "XHTML Basic 1.1", ""},
+	{"-//w3c//dtd html 3.2", "HTML 3.2", ""},
+	{"-//ietf//dtd html 2.0", "HTML 2.0", ""},
+}
+
+// knownSystemIDs is the fallback for doctypes with only a system identifier
+// (the end of the DTD URL, lowercase)
+var knownSystemIDs = []knownDoctype{
+	{"/html4/strict.dtd", "HTML 4.01", "Strict"},
+	{"/html4/loose.dtd", "HTML 4.01", "Transitional"},
+	{"/html4/frameset.dtd", "HTML 4.01", "Frameset"},
+	{"/xhtml1-strict.dtd", "XHTML 1.0", "Strict"},
+	{"/xhtml1-transitional.dtd", "XHTML 1.0", "Transitional"},
+	{"/xhtml1-frameset.dtd", "XHTML 1.0", "Frameset"},
+	{"/xhtml11.dtd", "XHTML 1.1", ""},
+}
+
+// detectDoctype finds the <!DOCTYPE> in the document prelude – it doesn't have to be
+// the first node: comments (and whitespace) may come before it – and identifies it
+func detectDoctype(doc *html.Node) Doctype {
+	for n := doc.FirstChild; n != nil; n = n.NextSibling {
+		if n.Type == html.DoctypeNode {
+			return identifyDoctype(n)
+		}
+		if n.Type == html.ElementNode {
+			break // Past the prelude: a doctype can't come after <html>
+		}
+	}
+	return Doctype{Version: "Unknown", Mode: ModeQuirks} // No doctype at all → quirks mode
+}
+
+// identifyDoctype reads the name and identifiers of a doctype node.
+// The parser puts the identifiers in attributes named "public" and "system".
+func identifyDoctype(n *html.Node) Doctype {
+	d := Doctype{Version: "Unknown", PublicID: attrValue(n, "public"), SystemID: attrValue(n, "system")}
+	hasSystem := hasAttr(n, "system")
+	public := strings.ToLower(strings.TrimSpace(d.PublicID))
+	system := strings.ToLower(strings.TrimSpace(d.SystemID))
+
+	switch {
+	case n.Data != "html":
+		// e.g. <!DOCTYPE svg>: not an HTML doctype
+	case !hasAttr(n, "public") && (system == "" || system == "about:legacy-compat"):
+		d.Version = "HTML5" // <!DOCTYPE html>, optionally with the XSLT-friendly legacy-compat
+	case public != "":
+		for _, k := range knownPublicIDs {
+			if strings.HasPrefix(public, k.prefix) {
+				d.Version, d.Variant = k.version, k.variant
+				break
+			}
+		}
+	default:
+		for _, k := range knownSystemIDs {
+			if strings.HasSuffix(system, k.prefix) {
+				d.Version, d.Variant = k.version, k.variant
+				break
+			}
+		}
+	}
+
+	d.Mode = doctypeMode(n.Data, public, system, hasSystem)
+	return d
+}
+
+// doctypeMode applies the HTML spec's rules for choosing the rendering mode
+// from a doctype (public and system are lowercase)
+func doctypeMode(name, public, system string, hasSystem bool) string {
+	if name != "html" || public == "html" ||
+		public == "-//w3o//dtd w3 html strict 3.0//en//" || public == "-/w3d/dtd html 4.0 transitional/en" ||
+		system == "http://www.ibm.com/data/dtd/v11/ibmxhtml1-transitional.dtd" {
+		return ModeQuirks
+	}
+	for _, q := range quirkyPublicIDs {
+		if strings.HasPrefix(public, q) {
+			return ModeQuirks
+		}
+	}
+
+	// HTML 4.01 Transitional/Frameset: quirks without a system identifier, limited quirks with one
+	html401Loose := strings.HasPrefix(public, "-//w3c//dtd html 4.01 transitional//") ||
+		strings.HasPrefix(public, "-//w3c//dtd html 4.01 frameset//")
+	if html401Loose && !hasSystem {
+		return ModeQuirks
+	}
+	if html401Loose ||
+		strings.HasPrefix(public, "-//w3c//dtd xhtml 1.0 transitional//") ||
+		strings.HasPrefix(public, "-//w3c//dtd xhtml 1.0 frameset//") {
+		return ModeLimitedQuirks
+	}
+	return ModeNoQuirks
+}
+
+// quirkyPublicIDs are the public identifier prefixes that put a browser in
+// quirks mode, as listed in the HTML spec (lowercase)
+var quirkyPublicIDs = []string{
+	"+//silmaril//dtd html pro v0r11 19970101//",
+	"-//advasoft ltd//dtd html 3.0 aswedit + extensions//",
+	"-//as//dtd html 3.0 aswedit + extensions//",
+	"-//ietf//dtd html 2.0 level 1//",
+	"-//ietf//dtd html 2.0 level 2//",
+	"-//ietf//dtd html 2.0 strict level 1//",
+	"-//ietf//dtd html 2.0 strict level 2//",
+	"-//ietf//dtd html 2.0 strict//",
+	"-//ietf//dtd html 2.0//",
+	"-//ietf//dtd html 2.1e//",
+	"-//ietf//dtd html 3.0//",
+	"-//ietf//dtd html 3.2 final//",
+	"-//ietf//dtd html 3.2//",
+	"-//ietf//dtd html 3//",
+	"-//ietf//dtd html level 0//",
+	"-//ietf//dtd html level 1//",
+	"-//ietf//dtd html level 2//",
+	"-//ietf//dtd html level 3//",
+	"-//ietf//dtd html strict level 0//",
+	"-//ietf//dtd html strict level 1//",
+	"-//ietf//dtd html strict level 2//",
+	"-//ietf//dtd html strict level 3//",
+	"-//ietf//dtd html strict//",
+	"-//ietf//dtd html//",
+	"-//metrius//dtd metrius presentational//",
+	"-//microsoft//dtd internet explorer 2.0 html strict//",
+	"-//microsoft//dtd internet explorer 2.0 html//",
+	"-//microsoft//dtd internet explorer 2.0 tables//",
+	"-//microsoft//dtd internet explorer 3.0 html strict//",
+	"-//microsoft//dtd internet explorer 3.0 html//",
+	"-//microsoft//dtd internet explorer 3.0 tables//",
+	"-//netscape comm. corp.//dtd html//",
+	"-//netscape comm. corp.//dtd strict html//",
+	"-//o'reilly and associates//dtd html 2.0//",
+	"-//o'reilly and associates//dtd html extended 1.0//",
+	"-//o'reilly and associates//dtd html extended relaxed 1.0//",
+	"-//softquad software//dtd hotmetal pro 6.0::19990601::extensions to html 4.0//",
+	"-//softquad//dtd hotmetal pro 4.0::19971010::extensions to html 4.0//",
+	"-//spyglass//dtd html 2.0 extended//",
+	"-//sq//dtd html 2.0 hotmetal + extensions//",
+	"-//sun microsystems corp.//dtd hotjava html//",
+	"-//sun microsystems corp.//dtd hotjava strict html//",
+	"-//w3c//dtd html 3 1995-03-24//",
+	"-//w3c//dtd html 3.2 draft//",
+	"-//w3c//dtd html 3.2 final//",
+	"-//w3c//dtd html 3.2//",
+	"-//w3c//dtd html 3.2s draft//",
+	"-//w3c//dtd html 4.0 frameset//",
+	"-//w3c//dtd html 4.0 transitional//",
+	"-//w3c//dtd html experimental 19960712//",
+	"-//w3c//dtd html experimental 970421//",
+	"-//w3c//dtd w3 html//",
+	"-//w3o//dtd w3 html 3.0//",
+	"-//webtechs//dtd mozilla html 2.0//",
+	"-//webtechs//dtd mozilla html//",
+}
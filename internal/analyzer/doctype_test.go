@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzePage_Doctype(t *testing.T) {
+	tests := []struct {
+		name, doctype string
+		version, mode string
+		publicID      string
+	}{
+		{"html5", `<!DOCTYPE html>`, "HTML5", ModeNoQuirks, ""},
+		{"html5 lowercase", `<!doctype HTML>`, "HTML5", ModeNoQuirks, ""},
+		{"legacy-compat", `<!DOCTYPE html SYSTEM "about:legacy-compat">`, "HTML5", ModeNoQuirks, ""},
+		{"comment before doctype", "<!-- generated -->\n  <!DOCTYPE html>", "HTML5", ModeNoQuirks, ""},
+		{"html 4.01 strict",
+			`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd">`,
+			"HTML 4.01 Strict", ModeNoQuirks, "-//W3C//DTD HTML 4.01//EN"},
+		{"html 4.01 transitional with system id",
+			`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN" "http://www.w3.org/TR/html4/loose.dtd">`,
+			"HTML 4.01 Transitional", ModeLimitedQuirks, "-//W3C//DTD HTML 4.01 Transitional//EN"},
+		{"html 4.01 frameset without system id",
+			`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Frameset//EN">`,
+			"HTML 4.01 Frameset", ModeQuirks, "-//W3C//DTD HTML 4.01 Frameset//EN"},
+		{"xhtml 1.0 transitional",
+			`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">`,
+			"XHTML 1.0 Transitional", ModeLimitedQuirks, "-//W3C//DTD XHTML 1.0 Transitional//EN"},
+		{"xhtml 1.1",
+			`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.1//EN" "http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd">`,
+			"XHTML 1.1", ModeNoQuirks, "-//W3C//DTD XHTML 1.1//EN"},
+		{"html 3.2", `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 3.2 Final//EN">`, "HTML 3.2", ModeQuirks, "-//W3C//DTD HTML 3.2 Final//EN"},
+		{"system id only", `<!DOCTYPE html SYSTEM "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd">`, "XHTML 1.0 Strict", ModeNoQuirks, ""},
+		{"unknown public id", `<!DOCTYPE html PUBLIC "-//ACME//DTD Web 1//EN">`, "Unknown", ModeNoQuirks, "-//ACME//DTD Web 1//EN"},
+		{"no doctype", ``, "Unknown", ModeQuirks, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := AnalyzePage(strings.NewReader(tt.doctype+"<html><head><title>x</title></head></html>"), "")
+			if err != nil {
+				t.Fatalf("AnalyzePage: %v", err)
+			}
+			if result.HTMLVersion != tt.version || result.Doctype.Mode != tt.mode || result.Doctype.PublicID != tt.publicID {
+				t.Errorf("got %q, mode %q, public id %q; want %q, %q, %q",
+					result.HTMLVersion, result.Doctype.Mode, result.Doctype.PublicID, tt.version, tt.mode, tt.publicID)
+			}
+		})
+	}
+}
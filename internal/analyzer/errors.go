@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error codes returned to API clients.
+// They are stable strings, so scripts can switch on them instead of
+// parsing the human-readable message.
+const (
+	ErrCodeBadRequest       = "bad_request"              // Body is not valid JSON, etc.
+	ErrCodeMethodNotAllowed = "method_not_allowed"       // Wrong HTTP verb
+	ErrCodeMissingURL       = "missing_url"              // No URL given
+	ErrCodeInvalidURL       = "invalid_url"              // URL doesn't look like http(s)://...
+	ErrCodeMissingHTML      = "missing_html"             // HTML upload/paste mode got no markup
+	ErrCodeRobotsDisallowed = "robots_disallowed"        // robots.txt disallows the URL
+	ErrCodeBlockedTarget    = "blocked_target"           // URL resolves to a private/internal address (SSRF guard)
+	ErrCodeFetchFailed      = "fetch_failed"             // DNS error, timeout, connection refused...
+	ErrCodeUpstreamStatus   = "upstream_status"          // Page answered with non-200
+	ErrCodeBodyTooLarge     = "body_too_large"           // Page is bigger than the fetcher's MaxBodySize
+	ErrCodeParseFailed      = "parse_failed"             // HTML could not be parsed
+	ErrCodeUnsupportedType  = "unsupported_content_type" // Page is a PDF, image, JSON... not HTML
+	ErrCodeSitemapNotFound  = "sitemap_not_found"        // No sitemap could be found or read
+	ErrCodeBatchTooLarge    = "batch_too_large"          // More URLs than MaxBatchURLs
+	ErrCodeQueueFull        = "queue_full"               // Background job queue is full
+	ErrCodeJobNotFound      = "job_not_found"            // Unknown or expired job ID
+	ErrCodeInternal         = "internal_error"           // Anything we didn't expect
+)
+
+// AnalysisError is the typed error produced by the analysis pipeline.
+// It carries everything a client needs:
+// - Status:  the HTTP status code the API should answer with
+// - Code:    a machine-readable error code (see ErrCode* above)
+// - Message: a human-readable explanation (also shown on the HTML error page)
+// - Details: optional extra fields, e.g. the upstream status code
+type AnalysisError struct {
+	Status  int               `json:"status"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// Error makes AnalysisError satisfy the built-in error interface
+func (e *AnalysisError) Error() string {
+	return e.Message
+}
+
+// newAnalysisError is a small helper so call sites stay on one line
+func newAnalysisError(status int, code, format string, args ...any) *AnalysisError {
+	return &AnalysisError{
+		Status:  status,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// asAnalysisError converts any error into an *AnalysisError.
+// Unknown errors become a generic 500 so we never leak internals.
+func asAnalysisError(err error) *AnalysisError {
+	var ae *AnalysisError
+	if errors.As(err, &ae) {
+		return ae
+	}
+	return newAnalysisError(http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+}
@@ -0,0 +1,328 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RedirectPolicy decides which redirects a Fetcher follows
+type RedirectPolicy string
+
+const (
+	RedirectFollow   RedirectPolicy = "follow"    // Follow any redirect (up to MaxRedirects)
+	RedirectSameHost RedirectPolicy = "same-host" // Only follow redirects that stay on the same host
+	RedirectNone     RedirectPolicy = "none"      // Never follow: the 3xx itself is the answer
+)
+
+// Fetcher downloads the page being analyzed. Every setting has a sensible
+// default in PageFetcher; main.go overrides them from FETCH_* env vars.
+type Fetcher struct {
+	Timeout        time.Duration     // Whole fetch, headers and body (0 = no limit)
+	MaxBodySize    int64             // Bytes; a bigger page fails with *BodyTooLargeError (0 = no limit)
+	UserAgent      string            // "" = the package's UserAgent
+	AcceptLanguage string            // Sent as Accept-Language ("" = header not sent)
+	Redirects      RedirectPolicy    // follow / same-host / none
+	MaxRedirects   int               // Redirects followed before giving up
+	Transport      http.RoundTripper // nil = the SSRF-guarded transport
+}
+
+// PageFetcher is used by AnalyzeURL (and so by every endpoint that fetches pages)
+var PageFetcher = &Fetcher{
+	Timeout:        15 * time.Second,
+	MaxBodySize:    10 << 20, // 10 MiB
+	AcceptLanguage: "en-US,en;q=0.9",
+	Redirects:      RedirectFollow,
+	MaxRedirects:   10,
+}
+
+// BodyTooLargeError is returned when a page is bigger than Fetcher.MaxBodySize
+type BodyTooLargeError struct {
+	Limit int64
+}
+
+func (e *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("response body is larger than %d bytes", e.Limit)
+}
+
+// FetchInfo is everything we learned about the HTTP side of a page fetch
+type FetchInfo struct {
+	URL         string            `json:"url"`                 // What we asked for
+	FinalURL    string            `json:"final_url"`           // Where we ended up after redirects
+	StatusCode  int               `json:"status_code"`         // Of the final response
+	Redirects   []Redirect        `json:"redirects,omitempty"` // Every hop, in order
+	ContentType string            `json:"content_type"`        // Content-Type header as sent, e.g. "text/html; charset=utf-8"
+	BodyBytes   int64             `json:"body_bytes"`          // Size of the downloaded body
+	Headers     map[string]string `json:"headers"`             // Final response headers (repeated ones joined with ", ")
+	Timings     FetchTimings      `json:"timings"`
+
+	header   http.Header          // Final response headers, unjoined (each Set-Cookie separate), for auditSecurity
+	tlsState *tls.ConnectionState // Final connection's TLS state (nil for http)
+	certErr  error                // Why tlsState's certificate chain isn't trusted (nil = it is)
+}
+
+// FetchTimings breaks the fetch down like a browser's network tab.
+// DNS/connect/TLS are 0 when a kept-alive connection was reused,
+// and add up over redirect hops.
+type FetchTimings struct {
+	DNSMS      int64 `json:"dns_ms"`      // Resolving host names
+	ConnectMS  int64 `json:"connect_ms"`  // TCP connects
+	TLSMS      int64 `json:"tls_ms"`      // TLS handshakes
+	TTFBMS     int64 `json:"ttfb_ms"`     // Start → first byte of the final response
+	DownloadMS int64 `json:"download_ms"` // Reading the body
+	TotalMS    int64 `json:"total_ms"`    // Start → body read
+}
+
+// Fetch downloads rawURL and reads the whole body (up to MaxBodySize).
+// The response status is not checked: a 404 comes back with its body and info.
+// robots.txt and the per-host limits apply to every hop (see politeRequest);
+// waiting for the first host happens before Timeout starts ticking.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*FetchInfo, []byte, error) {
+	resp, info, finish, err := f.open(ctx, rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	// === Read the body, one byte past the limit to notice a bigger one ===
+	var r io.Reader = resp.Body
+	if f.MaxBodySize > 0 {
+		r = io.LimitReader(resp.Body, f.MaxBodySize+1)
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if f.MaxBodySize > 0 && int64(len(body)) > f.MaxBodySize {
+		return nil, nil, &BodyTooLargeError{Limit: f.MaxBodySize}
+	}
+
+	info.BodyBytes = int64(len(body))
+	finish()
+	return info, body, nil
+}
+
+// open sends the request and returns the response with its body unread,
+// for callers that stream it (sitemaps). Closing the body ends the fetch.
+// finish records the download/total timings; call it once the body is read.
+func (f *Fetcher) open(ctx context.Context, rawURL string) (*http.Response, *FetchInfo, func(), error) {
+	release, err := enterRequest(ctx, rawURL)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// Free the host slot and pool token once the headers are in.
+	// checkRedirect swaps release for every hop, so call the latest one.
+	defer func() { release() }()
+
+	cancel := func() {}
+	if f.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, f.Timeout)
+	}
+
+	info := &FetchInfo{URL: rawURL}
+	timer := &fetchTimer{start: time.Now()}
+	req, err := newRequest(httptrace.WithClientTrace(ctx, timer.trace()), http.MethodGet, rawURL)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, err
+	}
+	if f.UserAgent != "" {
+		req.Header.Set("User-Agent", f.UserAgent)
+	}
+	if f.AcceptLanguage != "" {
+		req.Header.Set("Accept-Language", f.AcceptLanguage)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+
+	transport := f.Transport
+	if transport == nil {
+		transport = guardedTransport
+	}
+	var roots *x509.CertPool // nil = the system roots
+	if t, ok := transport.(*http.Transport); ok && t.TLSClientConfig != nil {
+		roots = t.TLSClientConfig.RootCAs
+	}
+	client := &http.Client{Transport: inspectingTransport(transport), CheckRedirect: f.checkRedirect(info, &release)}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, err
+	}
+	headersAt := time.Now()
+
+	info.FinalURL = resp.Request.URL.String()
+	info.StatusCode = resp.StatusCode
+	info.ContentType = resp.Header.Get("Content-Type")
+	info.Headers = make(map[string]string, len(resp.Header))
+	for name, values := range resp.Header {
+		info.Headers[name] = strings.Join(values, ", ")
+	}
+	info.header, info.tlsState = resp.Header, resp.TLS
+	info.certErr = verifyPeer(resp.TLS, roots)
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+	finish := func() {
+		end := time.Now()
+		info.Timings = timer.timings()
+		info.Timings.DownloadMS = end.Sub(headersAt).Milliseconds()
+		info.Timings.TotalMS = end.Sub(timer.start).Milliseconds()
+	}
+	return resp, info, finish, nil
+}
+
+// inspectingTransports caches one inspectingTransport clone per transport,
+// so page fetches keep reusing their connections
+var inspectingTransports sync.Map // *http.Transport → *http.Transport
+
+// inspectingTransport returns a clone of t that completes the TLS handshake
+// with any certificate, TLS 1.0 and every cipher suite Go knows. The page
+// still gets analyzed, and the security audit reports what's wrong with the
+// connection (verifyPeer checks the certificate after the fact).
+// Link checks and robots.txt keep using verified connections.
+func inspectingTransport(rt http.RoundTripper) http.RoundTripper {
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return rt // Not an HTTP transport (e.g. a test mock): nothing to loosen
+	}
+	if c, ok := inspectingTransports.Load(t); ok {
+		return c.(*http.Transport)
+	}
+
+	c := t.Clone()
+	if c.TLSClientConfig == nil {
+		c.TLSClientConfig = &tls.Config{}
+	}
+	c.TLSClientConfig.InsecureSkipVerify = true
+	c.TLSClientConfig.MinVersion = tls.VersionTLS10
+	c.TLSClientConfig.CipherSuites = nil
+	for _, suites := range [][]*tls.CipherSuite{tls.CipherSuites(), tls.InsecureCipherSuites()} {
+		for _, suite := range suites {
+			c.TLSClientConfig.CipherSuites = append(c.TLSClientConfig.CipherSuites, suite.ID)
+		}
+	}
+	actual, _ := inspectingTransports.LoadOrStore(t, c)
+	return actual.(*http.Transport)
+}
+
+// verifyPeer checks the certificate chain of a connection against roots,
+// the way the TLS handshake normally would (the host name is checked
+// separately, see tlsReport). nil state or certificates → nothing to check.
+func verifyPeer(state *tls.ConnectionState, roots *x509.CertPool) error {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+	for _, cert := range state.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// enterRequest is politeRequest plus a shared worker-pool token: page fetches
+// share the pool with link checks (see workerpool.go), so crawls, batches and
+// single analyses together never exceed MaxWorkers requests. The token is
+// taken after the host wait, like in checkLink. release frees both.
+func enterRequest(ctx context.Context, rawURL string) (release func(), err error) {
+	leave, err := politeRequest(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	Acquire()
+	return func() { Release(); leave() }, nil
+}
+
+// checkRedirect applies the redirect policy, records each hop in info
+// and makes every hop polite: robots.txt, the per-host limits and Crawl-delay
+// apply to the next URL like to the first one. *release is swapped for the
+// next hop's (the wait counts against the fetch timeout).
+func (f *Fetcher) checkRedirect(info *FetchInfo, release *func()) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		switch {
+		case f.Redirects == RedirectNone:
+			return http.ErrUseLastResponse // The 3xx is the final answer
+		case f.Redirects == RedirectSameHost && !strings.EqualFold(req.URL.Host, via[0].URL.Host):
+			return http.ErrUseLastResponse
+		case len(via) > f.MaxRedirects:
+			return fmt.Errorf("stopped after %d redirects", f.MaxRedirects)
+		}
+		// req.Response is the 3xx that sent us here
+		info.Redirects = append(info.Redirects, Redirect{URL: req.Response.Request.URL.String(), Status: req.Response.StatusCode})
+
+		// The previous hop has answered: free its host before waiting for the next one
+		(*release)()
+		*release = func() {}
+		next, err := enterRequest(req.Context(), req.URL.String())
+		if err != nil {
+			return err
+		}
+		*release = next
+		return nil
+	}
+}
+
+// fetchTimer collects httptrace events. Dials may run in parallel
+// (IPv4 and IPv6), so everything is behind a lock.
+type fetchTimer struct {
+	mu                            sync.Mutex
+	start                         time.Time
+	dnsStart, connStart, tlsStart time.Time
+	dns, connect, tls             time.Duration
+	firstByte                     time.Time
+}
+
+func (t *fetchTimer) trace() *httptrace.ClientTrace {
+	// since adds the time from *from to now to *total
+	since := func(from *time.Time, total *time.Duration) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if !from.IsZero() {
+			*total += time.Since(*from)
+		}
+	}
+	mark := func(at *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		*at = time.Now()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { mark(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { since(&t.dnsStart, &t.dns) },
+		ConnectStart:         func(string, string) { mark(&t.connStart) },
+		ConnectDone:          func(string, string, error) { since(&t.connStart, &t.connect) },
+		TLSHandshakeStart:    func() { mark(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { since(&t.tlsStart, &t.tls) },
+		GotFirstResponseByte: func() { mark(&t.firstByte) }, // The last one is the final response
+	}
+}
+
+// timings returns the DNS, connect, TLS and TTFB parts
+func (t *fetchTimer) timings() FetchTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ft := FetchTimings{DNSMS: t.dns.Milliseconds(), ConnectMS: t.connect.Milliseconds(), TLSMS: t.tls.Milliseconds()}
+	if !t.firstByte.IsZero() {
+		ft.TTFBMS = t.firstByte.Sub(t.start).Milliseconds()
+	}
+	return ft
+}
+
+// cancelOnClose releases the request's timeout context when the body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
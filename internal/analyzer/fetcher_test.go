@@ -0,0 +1,173 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetcher_Fetch(t *testing.T) {
+	var gotUA, gotLang string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/page", http.StatusMovedPermanently)
+		case "/away":
+			http.Redirect(w, r, "http://other.example/", http.StatusFound)
+		case "/big":
+			_, _ = w.Write([]byte(strings.Repeat("x", 2048)))
+		default:
+			gotUA, gotLang = r.UserAgent(), r.Header.Get("Accept-Language")
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Add("X-Test", "a")
+			w.Header().Add("X-Test", "b")
+			_, _ = w.Write([]byte("<title>hi</title>"))
+		}
+	}))
+	defer ts.Close()
+
+	t.Run("redirects, headers and settings", func(t *testing.T) {
+		f := &Fetcher{Timeout: 5 * time.Second, UserAgent: "test-bot/2", AcceptLanguage: "de", Redirects: RedirectFollow, MaxRedirects: 5}
+		info, body, err := f.Fetch(context.Background(), ts.URL+"/old")
+		if err != nil {
+			t.Fatalf("Fetch: %v", err)
+		}
+		if string(body) != "<title>hi</title>" || info.BodyBytes != int64(len(body)) {
+			t.Errorf("body = %q (%d bytes recorded)", body, info.BodyBytes)
+		}
+		if info.FinalURL != ts.URL+"/page" || info.StatusCode != http.StatusOK {
+			t.Errorf("FinalURL = %q, StatusCode = %d; want %s/page, 200", info.FinalURL, info.StatusCode, ts.URL)
+		}
+		wantRedirects := []Redirect{{URL: ts.URL + "/old", Status: http.StatusMovedPermanently}}
+		if !reflect.DeepEqual(info.Redirects, wantRedirects) {
+			t.Errorf("Redirects = %+v; want %+v", info.Redirects, wantRedirects)
+		}
+		if info.ContentType != "text/html; charset=utf-8" || info.Headers["X-Test"] != "a, b" {
+			t.Errorf("ContentType = %q, X-Test = %q", info.ContentType, info.Headers["X-Test"])
+		}
+		if gotUA != "test-bot/2" || gotLang != "de" {
+			t.Errorf("User-Agent = %q, Accept-Language = %q; want test-bot/2, de", gotUA, gotLang)
+		}
+		if info.Timings.TotalMS < info.Timings.TTFBMS {
+			t.Errorf("Timings = %+v; total should include the time to first byte", info.Timings)
+		}
+	})
+
+	t.Run("body size limit", func(t *testing.T) {
+		f := &Fetcher{MaxBodySize: 1024, Redirects: RedirectFollow}
+		_, _, err := f.Fetch(context.Background(), ts.URL+"/big")
+		var tooLarge *BodyTooLargeError
+		if !errors.As(err, &tooLarge) || tooLarge.Limit != 1024 {
+			t.Errorf("err = %v; want *BodyTooLargeError{1024}", err)
+		}
+	})
+
+	t.Run("redirect policies", func(t *testing.T) {
+		none := &Fetcher{Redirects: RedirectNone, MaxRedirects: 5}
+		if info, _, err := none.Fetch(context.Background(), ts.URL+"/old"); err != nil || info.StatusCode != http.StatusMovedPermanently {
+			t.Errorf("none: info = %+v, err = %v; want the 301 itself", info, err)
+		}
+		sameHost := &Fetcher{Redirects: RedirectSameHost, MaxRedirects: 5}
+		if info, _, err := sameHost.Fetch(context.Background(), ts.URL+"/away"); err != nil || info.StatusCode != http.StatusFound {
+			t.Errorf("same-host: info = %+v, err = %v; want the 302 to another host", info, err)
+		}
+		zero := &Fetcher{Redirects: RedirectFollow, MaxRedirects: 0}
+		if _, _, err := zero.Fetch(context.Background(), ts.URL+"/old"); err == nil || !strings.Contains(err.Error(), "stopped after 0 redirects") {
+			t.Errorf("MaxRedirects 0: err = %v; want a redirect limit error", err)
+		}
+	})
+}
+
+func TestAnalyzeURL_FetchInfo(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/moved":
+			http.Redirect(w, r, "/", http.StatusFound)
+		case "/docs":
+			http.Redirect(w, r, "/docs/", http.StatusMovedPermanently)
+		case "/docs/":
+			_, _ = w.Write([]byte(`<title>docs</title><a href="intro">intro</a>`))
+		default:
+			_, _ = w.Write([]byte("<title>home</title>"))
+		}
+	}))
+	defer ts.Close()
+
+	result, err := AnalyzeURL(context.Background(), ts.URL+"/moved", Options{})
+	if err != nil {
+		t.Fatalf("AnalyzeURL: %v", err)
+	}
+	if result.Fetch == nil || result.Fetch.StatusCode != http.StatusOK || len(result.Fetch.Redirects) != 1 {
+		t.Fatalf("Fetch = %+v; want a 200 after one redirect", result.Fetch)
+	}
+	if result.FinalURL != ts.URL+"/" {
+		t.Errorf("FinalURL = %q; want %s/", result.FinalURL, ts.URL)
+	}
+
+	// Relative links resolve against where the redirects landed
+	result, err = AnalyzeURL(context.Background(), ts.URL+"/docs", Options{})
+	if err != nil {
+		t.Fatalf("AnalyzeURL: %v", err)
+	}
+	if link := result.Links.Items[0]; link.URL != ts.URL+"/docs/intro" {
+		t.Errorf("link = %s; want %s/docs/intro", link.URL, ts.URL)
+	}
+
+	// A page bigger than the limit is refused with its own error code
+	old := PageFetcher.MaxBodySize
+	defer func() { PageFetcher.MaxBodySize = old }()
+	PageFetcher.MaxBodySize = 5
+	_, err = AnalyzeURL(context.Background(), ts.URL+"/", Options{})
+	var aerr *AnalysisError
+	if !errors.As(err, &aerr) || aerr.Code != ErrCodeBodyTooLarge {
+		t.Errorf("err = %v; want %s", err, ErrCodeBodyTooLarge)
+	}
+}
+
+func TestFetch_RedirectHopsWaitForTheirHost(t *testing.T) {
+	oldConc := HostConcurrency
+	defer func() { HostConcurrency = oldConc }()
+	HostConcurrency = 1
+
+	var targetHits atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		targetHits.Add(1)
+	}))
+	defer target.Close()
+	origin := httptest.NewServer(http.RedirectHandler(target.URL+"/", http.StatusFound))
+	defer origin.Close()
+
+	// Take the target host's only slot, as another request to it would
+	release, err := politeRequest(context.Background(), target.URL+"/")
+	if err != nil {
+		t.Fatalf("politeRequest: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := PageFetcher.Fetch(context.Background(), origin.URL+"/")
+		done <- err
+	}()
+
+	// The redirect must wait for the target's slot like a first request would
+	time.Sleep(50 * time.Millisecond)
+	if n := targetHits.Load(); n != 0 {
+		t.Fatalf("redirect target hit %d times while its host slot was taken", n)
+	}
+	release()
+
+	select {
+	case err := <-done:
+		if err != nil || targetHits.Load() != 1 {
+			t.Errorf("err = %v, target hits = %d; want the redirect followed once the slot is free", err, targetHits.Load())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fetch did not finish after the slot was released")
+	}
+}
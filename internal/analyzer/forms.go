@@ -0,0 +1,353 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Values of FormReport.Purpose
+const (
+	FormLogin         = "login"
+	FormSignup        = "signup"
+	FormPasswordReset = "password_reset"
+	FormSearch        = "search"
+	FormNewsletter    = "newsletter"
+	FormPayment       = "payment"
+	FormOther         = "other" // Nothing matched well enough
+)
+
+// formMinScore is the score a purpose needs before we believe it (below → "other");
+// HasLoginForm needs a login form with at least loginMinConfidence
+const (
+	formMinScore       = 0.3
+	loginMinConfidence = 0.5
+)
+
+// FormReport is one form on the page and what we think it is for
+type FormReport struct {
+	Purpose    string   `json:"purpose"`              // login / signup / password_reset / search / newsletter / payment / other
+	Confidence float64  `json:"confidence"`           // 0–1: how sure we are about Purpose
+	Signals    []string `json:"signals"`              // Why, e.g. "password field", "autocomplete=username"
+	Path       string   `json:"path"`                 // CSS-like path to the <form> (or the element grouping the fields)
+	Action     string   `json:"action,omitempty"`     // action attribute as written
+	Method     string   `json:"method"`               // GET or POST
+	Fields     int      `json:"fields"`               // Visible inputs, selects and textareas
+	Standalone bool     `json:"standalone,omitempty"` // Fields not inside a <form> (typical for SPAs)
+}
+
+// ConfidencePercent is Confidence as 0–100 (used by the results template)
+func (f FormReport) ConfidencePercent() int {
+	return int(math.Round(f.Confidence * 100))
+}
+
+// Wording that hints at a purpose, matched against the form's own text
+// (buttons, legend, headings, labels, placeholders) and its action/id/class
+var (
+	loginWords      = regexp.MustCompile(`\b(log ?in|sign ?in|log ?on)\b`)
+	signupWords     = regexp.MustCompile(`\b(sign ?up|register|registration|create (an )?account|join)\b`)
+	resetWords      = regexp.MustCompile(`\b(forgot|reset|recover|lost)\b.*\bpassword|\bpassword (reset|recovery)`)
+	searchWords     = regexp.MustCompile(`\bsearch`)
+	newsletterWords = regexp.MustCompile(`\b(newsletter|subscribe|mailing list)`)
+	paymentWords    = regexp.MustCompile(`\b(pay|payment|checkout|billing|credit card|card number)\b`)
+	usernameHints   = regexp.MustCompile(`\b(user|username|login|email|e mail|account)\b`)
+	cardHints       = regexp.MustCompile(`\b(card ?number|cc ?(number|num|exp|csc)|cvc|cvv|expiry|expiration)\b`)
+	searchNames     = map[string]bool{"q": true, "query": true, "search": true, "s": true, "keywords": true}
+)
+
+// formFields is what we learn from one form's controls and text
+type formFields struct {
+	visible      int // Everything the user fills in
+	textual      int // The same minus checkboxes and radio buttons
+	passwords    int
+	autocomplete map[string]int // autocomplete token → count, e.g. "current-password": 1
+	emails       int            // type=email or autocomplete=email
+	usernameHint string         // The first field that looks like a username, for the signal text
+	searchField  string         // Why a field looks like a search box
+	cardHint     string         // The first field that looks like a card field
+	text         string         // Lowercased wording (see normalizeWords)
+}
+
+// classifyForm works out what form (or a group of standalone fields) is for.
+// Nested <form> elements are skipped: they get their own report.
+func classifyForm(form *html.Node, standalone bool) FormReport {
+	fields := collectFormFields(form)
+
+	// Each purpose collects a score and the signals behind it
+	scores := make(map[string]float64)
+	signals := make(map[string][]string)
+	add := func(purpose string, weight float64, signal string) {
+		scores[purpose] += weight
+		signals[purpose] = append(signals[purpose], signal)
+	}
+
+	// === Login ===
+	if fields.passwords == 1 {
+		add(FormLogin, 0.3, "one password field")
+	}
+	if fields.autocomplete["current-password"] > 0 {
+		add(FormLogin, 0.4, "autocomplete=current-password")
+	}
+	if fields.passwords > 0 && fields.usernameHint != "" {
+		add(FormLogin, 0.2, "username field ("+fields.usernameHint+")")
+	}
+	if loginWords.MatchString(fields.text) {
+		add(FormLogin, 0.3, "login wording")
+	}
+
+	// === Signup ===
+	if fields.autocomplete["new-password"] > 0 && !resetWords.MatchString(fields.text) {
+		add(FormSignup, 0.4, "autocomplete=new-password")
+	}
+	if fields.passwords >= 2 {
+		add(FormSignup, 0.4, "password confirmation field")
+	}
+	if fields.passwords > 0 && fields.textual >= 3 {
+		add(FormSignup, 0.2, "more fields than a login needs")
+	}
+	if fields.autocomplete["given-name"]+fields.autocomplete["family-name"]+fields.autocomplete["name"] > 0 {
+		add(FormSignup, 0.1, "name fields")
+	}
+	if signupWords.MatchString(fields.text) {
+		add(FormSignup, 0.5, "signup wording")
+	}
+
+	// === Password reset ===
+	if resetWords.MatchString(fields.text) {
+		add(FormPasswordReset, 0.6, "password reset wording")
+		if fields.passwords == 0 && (fields.emails > 0 || fields.usernameHint != "") {
+			add(FormPasswordReset, 0.3, "only asks for the account")
+		}
+		if fields.autocomplete["new-password"] > 0 {
+			add(FormPasswordReset, 0.3, "autocomplete=new-password")
+		}
+	}
+
+	// === Search ===
+	if fields.searchField != "" {
+		add(FormSearch, 0.5, fields.searchField)
+	}
+	if strings.EqualFold(attrValue(form, "role"), "search") {
+		add(FormSearch, 0.5, "role=search")
+	}
+	if searchWords.MatchString(fields.text) {
+		add(FormSearch, 0.3, "search wording")
+	}
+
+	// === Newsletter ===
+	if fields.passwords == 0 && fields.emails == 1 && fields.visible <= 3 {
+		add(FormNewsletter, 0.3, "single email field")
+	}
+	if newsletterWords.MatchString(fields.text) {
+		add(FormNewsletter, 0.5, "newsletter wording")
+	}
+
+	// === Payment ===
+	ccFields := 0
+	for token, count := range fields.autocomplete {
+		if strings.HasPrefix(token, "cc-") {
+			ccFields += count
+		}
+	}
+	if ccFields > 0 {
+		add(FormPayment, 0.3+0.2*float64(min(ccFields, 3)), fmt.Sprintf("%d card autocomplete field(s)", ccFields))
+	} else if fields.cardHint != "" {
+		add(FormPayment, 0.5, "card field ("+fields.cardHint+")")
+	}
+	if paymentWords.MatchString(fields.text) {
+		add(FormPayment, 0.3, "payment wording")
+	}
+
+	// === Pick the best purpose (ties: the order below wins) ===
+	report := FormReport{
+		Purpose:    FormOther,
+		Signals:    []string{},
+		Path:       cssPath(form),
+		Action:     attrValue(form, "action"),
+		Method:     strings.ToUpper(attrValue(form, "method")),
+		Fields:     fields.visible,
+		Standalone: standalone,
+	}
+	if report.Method != "POST" {
+		report.Method = "GET" // The HTML default (and what anything else falls back to)
+	}
+	best := 0.0
+	for _, purpose := range []string{FormPasswordReset, FormSignup, FormLogin, FormPayment, FormSearch, FormNewsletter} {
+		if scores[purpose] > best {
+			best = scores[purpose]
+			report.Purpose = purpose
+		}
+	}
+	if best < formMinScore {
+		report.Purpose = FormOther
+		return report
+	}
+	report.Confidence = math.Round(math.Min(best, 1)*100) / 100
+	report.Signals = signals[report.Purpose]
+	return report
+}
+
+// collectFormFields walks a form's controls and gathers its wording
+func collectFormFields(form *html.Node) formFields {
+	f := formFields{autocomplete: make(map[string]int)}
+	words := []string{attrValue(form, "action"), attrValue(form, "id"), attrValue(form, "class"),
+		attrValue(form, "name"), attrValue(form, "aria-label")}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.Data {
+			case "form":
+				continue // Its own report
+
+			case "button", "legend", "label", "h1", "h2", "h3", "h4", "h5", "h6":
+				words = append(words, textContent(c))
+
+			case "input", "select", "textarea":
+				f.addField(c)
+				words = append(words, attrValue(c, "placeholder"), attrValue(c, "aria-label"))
+				if t := strings.ToLower(attrValue(c, "type")); t == "submit" || t == "button" {
+					words = append(words, attrValue(c, "value"))
+				}
+			}
+			walk(c)
+		}
+	}
+	walk(form)
+
+	f.text = normalizeWords(strings.Join(words, " "))
+	return f
+}
+
+// addField records one control's type, autocomplete token and name hints
+func (f *formFields) addField(n *html.Node) {
+	inputType := strings.ToLower(attrValue(n, "type"))
+	if n.Data != "input" {
+		inputType = n.Data // select / textarea
+	}
+	switch inputType {
+	case "hidden", "submit", "button", "reset", "image":
+		return // Not something the user fills in
+	}
+	f.visible++
+	if inputType != "checkbox" && inputType != "radio" {
+		f.textual++
+	}
+
+	// autocomplete may hold several tokens, e.g. "section-login username"
+	tokens := strings.Fields(strings.ToLower(attrValue(n, "autocomplete")))
+	for _, token := range tokens {
+		f.autocomplete[token]++
+	}
+	hint := normalizeWords(strings.Join([]string{attrValue(n, "name"), attrValue(n, "id"),
+		attrValue(n, "placeholder"), attrValue(n, "aria-label")}, " "))
+
+	switch {
+	case inputType == "password":
+		f.passwords++
+	case inputType == "email" || slices.Contains(tokens, "email"):
+		f.emails++
+		if f.usernameHint == "" {
+			f.usernameHint = "email"
+		}
+	case slices.Contains(tokens, "username"):
+		if f.usernameHint == "" {
+			f.usernameHint = "autocomplete=username"
+		}
+	case (inputType == "" || inputType == "text" || inputType == "tel") && usernameHints.MatchString(hint):
+		if f.usernameHint == "" {
+			f.usernameHint = "name/id/placeholder hint"
+		}
+	}
+
+	switch {
+	case f.searchField != "":
+	case inputType == "search":
+		f.searchField = "type=search field"
+	case searchNames[strings.ToLower(attrValue(n, "name"))]:
+		f.searchField = fmt.Sprintf("field named %q", attrValue(n, "name"))
+	}
+	if f.cardHint == "" && cardHints.MatchString(hint) {
+		f.cardHint = strings.TrimSpace(attrValue(n, "name"))
+	}
+}
+
+// standaloneContainer picks the element that groups a control living outside any <form>:
+// the nearest role="form"/"search" ancestor, else the nearest one with a button, else <body>
+func standaloneContainer(n *html.Node) *html.Node {
+	var body *html.Node
+	for p := n.Parent; p != nil && p.Type == html.ElementNode; p = p.Parent {
+		switch strings.ToLower(attrValue(p, "role")) {
+		case "form", "search":
+			return p
+		}
+		if hasButton(p) {
+			return p
+		}
+		body = p
+	}
+	return body
+}
+
+// hasButton reports whether n contains a button (or a submit input)
+func hasButton(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if c.Data == "button" || strings.EqualFold(attrValue(c, "role"), "button") ||
+			c.Data == "input" && strings.EqualFold(attrValue(c, "type"), "submit") {
+			return true
+		}
+		if hasButton(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// insideForm reports whether n has a <form> ancestor
+func insideForm(n *html.Node) bool {
+	return enclosingForm(n) != nil
+}
+
+// enclosingForm is the nearest <form> around n (nil if none)
+func enclosingForm(n *html.Node) *html.Node {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.Data == "form" {
+			return p
+		}
+	}
+	return nil
+}
+
+// normalizeWords lowercases s and splits identifiers into words, so
+// "loginForm", "sign-in" and "user_name" match \blogin\b, "sign in" and "user name"
+func normalizeWords(s string) string {
+	var sb strings.Builder
+	prevLower := false
+	for _, r := range s {
+		switch {
+		case r == '_' || r == '-' || r == '/' || r == '.' || r == '[' || r == ']':
+			sb.WriteByte(' ')
+			prevLower = false
+		case r >= 'A' && r <= 'Z':
+			if prevLower {
+				sb.WriteByte(' ') // camelCase boundary
+			}
+			sb.WriteRune(r + ('a' - 'A'))
+			prevLower = false
+		default:
+			sb.WriteRune(r)
+			prevLower = r >= 'a' && r <= 'z'
+		}
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzePage_Forms(t *testing.T) {
+	tests := []struct {
+		name    string
+		page    string
+		purpose string
+		login   bool // HasLoginForm
+	}{
+		{"login via autocomplete",
+			`<form method="post"><input id="a" autocomplete="username"><input type="password" autocomplete="current-password"><button>Continue</button></form>`,
+			FormLogin, true},
+		{"login via placeholder",
+			`<form><input placeholder="Email address"><input type="password"><button>Sign in</button></form>`,
+			FormLogin, true},
+		{"signup",
+			`<form action="/register"><input name="firstName"><input type="email" name="email">
+			 <input type="password" autocomplete="new-password"><input type="password" name="confirm"><button>Create account</button></form>`,
+			FormSignup, false},
+		{"password reset",
+			`<form><h2>Forgot your password?</h2><input type="email" name="email"><button>Send reset link</button></form>`,
+			FormPasswordReset, false},
+		{"search",
+			`<form role="search" action="/find"><input type="search" name="q"></form>`,
+			FormSearch, false},
+		{"newsletter",
+			`<form><input type="email" placeholder="you@example.com"><button>Subscribe to our newsletter</button></form>`,
+			FormNewsletter, false},
+		{"payment",
+			`<form><input autocomplete="cc-number"><input autocomplete="cc-exp"><input autocomplete="cc-csc"><button>Pay now</button></form>`,
+			FormPayment, false},
+		{"spa login without form",
+			`<div class="modal"><div class="login-box"><input name="userName"><input type="password"><button>Log in</button></div></div>`,
+			FormLogin, true},
+		{"comment box",
+			`<form><textarea name="comment"></textarea><button>Post</button></form>`,
+			FormOther, false},
+		{"joined/joint is not signup wording",
+			`<form class="joint-venture"><h3>Joined the discussion?</h3><textarea name="comment"></textarea><button>Post</button></form>`,
+			FormOther, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := AnalyzePage(strings.NewReader("<html><body>"+tt.page+"</body></html>"), "")
+			if err != nil {
+				t.Fatalf("AnalyzePage: %v", err)
+			}
+			if len(result.Forms) != 1 {
+				t.Fatalf("Forms = %+v; want 1", result.Forms)
+			}
+			f := result.Forms[0]
+			if f.Purpose != tt.purpose {
+				t.Errorf("Purpose = %q (%.2f, %v); want %q", f.Purpose, f.Confidence, f.Signals, tt.purpose)
+			}
+			if tt.purpose != FormOther && (f.Confidence < formMinScore || len(f.Signals) == 0) {
+				t.Errorf("Confidence = %.2f, Signals = %v; want a score and the reasons for it", f.Confidence, f.Signals)
+			}
+			if result.HasLoginForm != tt.login {
+				t.Errorf("HasLoginForm = %v; want %v", result.HasLoginForm, tt.login)
+			}
+		})
+	}
+}
+
+func TestAnalyzePage_Forms_Standalone(t *testing.T) {
+	page := `<html><body>
+<form action="/search"><input name="q"></form>
+<div id="app"><section><input type="email" autocomplete="username"><input type="password"><div role="button">Go</div></section></div>
+<input type="hidden" name="csrf">
+</body></html>`
+
+	result, err := AnalyzePage(strings.NewReader(page), "")
+	if err != nil {
+		t.Fatalf("AnalyzePage: %v", err)
+	}
+	if len(result.Forms) != 2 {
+		t.Fatalf("Forms = %+v; want the <form> and the standalone login fields", result.Forms)
+	}
+	if f := result.Forms[0]; f.Purpose != FormSearch || f.Standalone {
+		t.Errorf("Forms[0] = %+v; want a search <form>", f)
+	}
+	if f := result.Forms[1]; f.Purpose != FormLogin || !f.Standalone || f.Path != "div#app > section" {
+		t.Errorf("Forms[1] = %+v; want standalone login at div#app > section", f)
+	}
+}
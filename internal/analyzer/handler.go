@@ -10,13 +10,24 @@ import (
 )
 
 type pageData struct {
-	URL          string
-	HTMLVersion  string
-	Title        string
-	Headings     map[string]int
-	Links        Links
-	HasLoginForm bool
-	Error        string
+	URL            string
+	Uploaded       bool // HTML was uploaded/pasted instead of fetched from URL
+	HTMLVersion    string
+	Doctype        Doctype    // Identifiers + quirks mode behind HTMLVersion
+	Fetch          *FetchInfo // Status, headers, timings (nil for uploaded HTML)
+	Encoding       *Encoding  // Detected charset + mismatches (nil for uploaded HTML)
+	Security       *Security  // Graded headers, cookies, TLS (nil for uploaded HTML)
+	Title          string
+	Headings       map[string]int
+	Links          Links
+	HasLoginForm   bool
+	Forms          []FormReport   // Every form with its purpose (login, signup, search...)
+	SEO            SEO            // Meta description, canonical, Open Graph... with issues
+	StructuredData StructuredData // schema.org items + JSON-LD parse errors
+	Accessibility  Accessibility  // Missing alt text, labels, skipped headings...
+	MixedContent   MixedContent   // http:// resources on an https page
+	Cache          CacheInfo      // Was this served from cache, and how old is it?
+	Error          string
 }
 
 var (
@@ -52,17 +63,10 @@ func AnalyzeHandler(log *logrus.Logger) http.HandlerFunc {
 		// === STEP 2: Get the URL from form data ===
 		// User should send: <form><input name="url" value="https://example.com"></form>
 		rawURL := r.FormValue("url")
-		if rawURL == "" {
-			// No URL provided → show friendly error
-			renderError(w, "URL is required")
-			return
-		}
 
-		// === STEP 3: Validate URL format using regex ===
-		// urlRegex is probably defined elsewhere like: ^https?://.*
-		// It makes sure the URL looks valid (http:// or https://, etc.)
-		if !urlRegex.MatchString(rawURL) {
-			renderError(w, "Invalid URL format")
+		// === STEP 3: Validate URL (required + http(s):// format) ===
+		if err := ValidateURL(rawURL); err != nil {
+			renderError(w, err.Error()) // e.g. "URL is required"
 			return
 		}
 
@@ -72,45 +76,38 @@ func AnalyzeHandler(log *logrus.Logger) http.HandlerFunc {
 			"url": rawURL,
 		}).Info("Starting analysis")
 
-		// === STEP 5: Download the webpage ===
-		resp, err := http.Get(rawURL) // Simple HTTP GET request
+		// === STEP 5: Download + parse + analyze (see AnalyzeURLCached) ===
+		// Shared with the JSON API so both always behave the same.
+		// Ticking "refresh" on the form skips the cache lookup.
+		refresh := r.FormValue("refresh") != ""
+		result, cache, err := AnalyzeURLCached(r.Context(), rawURL, refresh, Options{})
 		if err != nil {
-			// Network error, timeout, bad domain, etc.
-			renderError(w, fmt.Sprintf("Failed to fetch URL: %v", err))
-			return
-		}
-		// Always close the response body to prevent memory leaks
-		defer resp.Body.Close()
-
-		// === STEP 6: Check if page loaded successfully (200 OK) ===
-		if resp.StatusCode != http.StatusOK {
-			// 404, 500, 403, etc. → page not available
-			renderError(w, fmt.Sprintf("URL unreachable – HTTP %d %s", 
-				resp.StatusCode, 
-				http.StatusText(resp.StatusCode))) // e.g., "404 Not Found"
+			// Fetch error, non-200 page, broken HTML... → friendly message
+			renderError(w, err.Error())
 			return
 		}
 
-		// === STEP 7: Parse the HTML and analyze it ===
-		// This uses the AnalyzePage function from earlier
-		result, err := AnalyzePage(resp.Body, rawURL)
-		if err != nil {
-			// HTML is broken, malformed, etc.
-			renderError(w, fmt.Sprintf("HTML parsing error: %v", err))
-			return
-		}
-
-		// === STEP 8: Prepare data to show in HTML template ===
+		// === STEP 6: Prepare data to show in HTML template ===
 		data := pageData{
-			URL:          rawURL,
-			HTMLVersion:  result.HTMLVersion,  // e.g., "HTML5"
-			Title:        result.Title,        // <title> content
-			Headings:     result.Headings,     // {"h1": 1, "h2": 3, ...}
-			Links:        result.Links,        // internal/external/broken counts
-			HasLoginForm: result.HasLoginForm, // true if login form detected
+			URL:            rawURL,
+			HTMLVersion:    result.HTMLVersion,    // e.g., "HTML5"
+			Doctype:        result.Doctype,        // public/system id, rendering mode
+			Fetch:          result.Fetch,          // final URL, headers, timings
+			Encoding:       result.Encoding,       // charset and where it came from
+			Security:       result.Security,       // graded headers, cookies, TLS
+			Title:          result.Title,          // <title> content
+			Headings:       result.Headings,       // {"h1": 1, "h2": 3, ...}
+			Links:          result.Links,          // internal/external/broken counts
+			HasLoginForm:   result.HasLoginForm,   // true if login form detected
+			Forms:          result.Forms,          // purpose + confidence per form
+			SEO:            result.SEO,            // meta description, canonical, Open Graph...
+			StructuredData: result.StructuredData, // JSON-LD, microdata, RDFa items
+			Accessibility:  result.Accessibility,  // WCAG-oriented findings
+			MixedContent:   result.MixedContent,   // active/passive http:// resources
+			Cache:          cache,                 // hit/miss + age
 		}
 
-		// === STEP 9: Render the result using an HTML template ===
+		// === STEP 7: Render the result using an HTML template ===
 		// Tmpl is a global *html/template.Template defined elsewhere
 		if err := Tmpl.Execute(w, data); err != nil {
 			// If template fails (syntax error, missing field, etc.)
@@ -127,4 +124,4 @@ func AnalyzeHandler(log *logrus.Logger) http.HandlerFunc {
 func renderError(w http.ResponseWriter, msg string) {
 	data := pageData{Error: msg}
 	_ = Tmpl.Execute(w, data) // ignore error – we are already in an error path
-}
\ No newline at end of file
+}
@@ -0,0 +1,280 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// JobState is where an asynchronous analysis currently is
+type JobState string
+
+const (
+	JobQueued        JobState = "queued"         // Waiting for a free worker
+	JobFetching      JobState = "fetching"       // Downloading + parsing the page
+	JobCheckingLinks JobState = "checking_links" // Page parsed, links being checked
+	JobDone          JobState = "done"           // Result is ready
+	JobFailed        JobState = "failed"         // Error is set
+)
+
+// JobProgress counts finished link checks
+type JobProgress struct {
+	LinksChecked int `json:"links_checked"`
+	LinksTotal   int `json:"links_total"`
+}
+
+// Job is one asynchronous analysis, as returned by GET /api/v1/jobs/{id}
+type Job struct {
+	ID         string          `json:"id"`
+	URL        string          `json:"url"`
+	State      JobState        `json:"state"`
+	Progress   JobProgress     `json:"progress"`
+	Cache      CacheInfo       `json:"cache"`
+	Result     *AnalysisResult `json:"result,omitempty"` // Set when State == done
+	Error      *AnalysisError  `json:"error,omitempty"`  // Set when State == failed
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  time.Time       `json:"started_at,omitzero"`
+	FinishedAt time.Time       `json:"finished_at,omitzero"`
+
+	refresh bool // Skip the cache for this job
+}
+
+// JobManager runs analyses in the background on a fixed number of workers.
+// - Submit never blocks: if the queue is full the job is refused (503)
+// - Finished jobs are kept for `retention`, then forgotten (by Submit, Get and a janitor)
+type JobManager struct {
+	mu        sync.Mutex
+	jobs      map[string]*Job
+	queue     chan *Job
+	retention time.Duration
+	log       *logrus.Logger
+	stop      chan struct{} // Closed by Close to stop the janitor
+	closeOnce sync.Once
+}
+
+// janitorInterval is how often the janitor looks for expired jobs
+// (more often when the retention is shorter than that)
+var janitorInterval = time.Minute
+
+// NewJobManager starts `workers` goroutines that take jobs from a queue
+// holding at most queueSize waiting jobs.
+func NewJobManager(log *logrus.Logger, workers, queueSize int, retention time.Duration) *JobManager {
+	m := &JobManager{
+		jobs:      make(map[string]*Job),
+		queue:     make(chan *Job, queueSize),
+		retention: retention,
+		log:       log,
+		stop:      make(chan struct{}),
+	}
+	for i := 0; i < workers; i++ {
+		go m.worker()
+	}
+	go m.janitor(min(janitorInterval, max(retention, time.Millisecond)))
+	return m
+}
+
+// Close stops the janitor. Queued and running jobs are left alone.
+func (m *JobManager) Close() {
+	m.closeOnce.Do(func() { close(m.stop) })
+}
+
+// janitor purges expired jobs every interval, so their results don't stay
+// in memory when nobody submits or polls anymore
+func (m *JobManager) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.mu.Lock()
+			m.purgeExpiredLocked()
+			m.mu.Unlock()
+		}
+	}
+}
+
+// Submit validates the URL and queues a new job.
+// Returns a snapshot of the queued job, or an *AnalysisError.
+func (m *JobManager) Submit(rawURL string, refresh bool) (Job, error) {
+	if err := ValidateURL(rawURL); err != nil {
+		return Job{}, err
+	}
+
+	job := &Job{
+		ID:        newJobID(),
+		URL:       rawURL,
+		State:     JobQueued,
+		CreatedAt: time.Now(),
+		refresh:   refresh,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.purgeExpiredLocked()
+
+	// Non-blocking send: a full queue means we're overloaded → tell the client
+	select {
+	case m.queue <- job:
+	default:
+		return Job{}, newAnalysisError(http.StatusServiceUnavailable, ErrCodeQueueFull, "Too many jobs queued, try again later")
+	}
+
+	m.jobs[job.ID] = job
+	return *job, nil
+}
+
+// Get returns a snapshot of the job (safe to read while it keeps running)
+func (m *JobManager) Get(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.purgeExpiredLocked() // An expired job is gone, even if the janitor hasn't run yet
+	job, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// worker runs queued jobs one after another, forever
+func (m *JobManager) worker() {
+	for job := range m.queue {
+		m.run(job)
+	}
+}
+
+// run executes one job, updating its state as the pipeline reports progress
+func (m *JobManager) run(job *Job) {
+	m.update(job, func(j *Job) {
+		j.State = JobFetching
+		j.StartedAt = time.Now()
+	})
+
+	hooks := Hooks{
+		OnParsed: func(_ AnalysisResult, linkCount int) {
+			m.update(job, func(j *Job) {
+				j.State = JobCheckingLinks
+				j.Progress.LinksTotal = linkCount
+			})
+		},
+		OnLinkChecked: func(_ LinkReport, done, total int) {
+			m.update(job, func(j *Job) {
+				j.Progress.LinksChecked = done
+				j.Progress.LinksTotal = total
+			})
+		},
+	}
+
+	// The submitting request is long gone, so use a fresh context
+	result, cache, err := AnalyzeURLCached(context.Background(), job.URL, job.refresh, Options{Hooks: hooks})
+
+	var final JobState
+	m.update(job, func(j *Job) {
+		defer func() { final = j.State }()
+		j.FinishedAt = time.Now()
+		if err != nil {
+			j.State = JobFailed
+			j.Error = asAnalysisError(err)
+			return
+		}
+		j.State = JobDone
+		j.Cache = cache
+		j.Result = result
+		// Cache hits skip the hooks, so fill in the final progress here
+		j.Progress = JobProgress{LinksChecked: len(result.Links.Items), LinksTotal: len(result.Links.Items)}
+	})
+
+	m.log.WithFields(logrus.Fields{
+		"job":   job.ID,
+		"url":   job.URL,
+		"state": final,
+	}).Info("Job finished")
+}
+
+// update applies fn to the job while holding the lock
+func (m *JobManager) update(job *Job, fn func(*Job)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fn(job)
+}
+
+// purgeExpiredLocked forgets finished jobs older than the retention period (caller holds mu)
+func (m *JobManager) purgeExpiredLocked() {
+	cutoff := time.Now().Add(-m.retention)
+	for id, job := range m.jobs {
+		if !job.FinishedAt.IsZero() && job.FinishedAt.Before(cutoff) {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// newJobID returns a random 128-bit hex ID (unguessable, so job results stay private)
+func newJobID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) // crypto/rand never fails on supported platforms
+	return hex.EncodeToString(b)
+}
+
+// apiJobRequest is the JSON body accepted by POST /api/v1/jobs
+type apiJobRequest struct {
+	URL     string `json:"url"`
+	Refresh bool   `json:"refresh"`
+}
+
+// JobSubmitHandler serves POST /api/v1/jobs.
+// Answers 202 Accepted straight away with the job ID and a Location header.
+func JobSubmitHandler(log *logrus.Logger, m *JobManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeAPIError(w, newAnalysisError(http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "Method not allowed"))
+			return
+		}
+
+		var req apiJobRequest
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			writeAPIError(w, err)
+			return
+		}
+
+		job, err := m.Submit(req.URL, req.Refresh)
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+
+		log.WithFields(logrus.Fields{
+			"job": job.ID,
+			"url": job.URL,
+		}).Info("Job queued")
+
+		w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+// JobStatusHandler serves GET /api/v1/jobs/{id}
+func JobStatusHandler(m *JobManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeAPIError(w, newAnalysisError(http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "Method not allowed"))
+			return
+		}
+
+		job, ok := m.Get(r.PathValue("id"))
+		if !ok {
+			writeAPIError(w, newAnalysisError(http.StatusNotFound, ErrCodeJobNotFound, "Job not found (unknown ID or expired)"))
+			return
+		}
+		writeJSON(w, http.StatusOK, job)
+	}
+}
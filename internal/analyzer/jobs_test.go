@@ -0,0 +1,179 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// waitForJob polls the manager until the job reaches a final state
+func waitForJob(t *testing.T, m *JobManager, id string) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, ok := m.Get(id)
+		if !ok {
+			t.Fatalf("job %s disappeared", id)
+		}
+		if job.State == JobDone || job.State == JobFailed {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish in time", id)
+	return Job{}
+}
+
+func TestJobManager(t *testing.T) {
+	// Save original, restore after – links are "checked" by a mock
+	oldClient := httpClient
+	defer func() { httpClient = oldClient }()
+	httpClient = &http.Client{
+		Transport: mockTransport(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("")),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`<title>Job</title><a href="/a">a</a><a href="/b">b</a><a href="mailto:x@y">m</a>`))
+	}))
+	defer ts.Close()
+
+	m := NewJobManager(logrus.New(), 2, 10, time.Hour)
+
+	t.Run("successful job reports progress and result", func(t *testing.T) {
+		queued, err := m.Submit(ts.URL, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if queued.ID == "" || queued.State != JobQueued {
+			t.Fatalf("queued job = %+v; want ID + state queued", queued)
+		}
+
+		job := waitForJob(t, m, queued.ID)
+		if job.State != JobDone || job.Result == nil || job.Result.Title != "Job" {
+			t.Fatalf("job = %+v; want done with title", job)
+		}
+		if job.Progress != (JobProgress{LinksChecked: 3, LinksTotal: 3}) {
+			t.Errorf("Progress = %+v; want 3/3", job.Progress)
+		}
+	})
+
+	t.Run("failed job carries the typed error", func(t *testing.T) {
+		queued, err := m.Submit(ts.URL+"/missing", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		job := waitForJob(t, m, queued.ID)
+		if job.State != JobFailed || job.Error == nil || job.Error.Code != ErrCodeUpstreamStatus {
+			t.Fatalf("job = %+v; want failed with %s", job, ErrCodeUpstreamStatus)
+		}
+	})
+
+	t.Run("invalid URL is refused up front", func(t *testing.T) {
+		if _, err := m.Submit("not-a-url", false); err == nil {
+			t.Fatal("expected validation error")
+		}
+	})
+}
+
+func TestJobManager_QueueFull(t *testing.T) {
+	// No workers → nothing drains the queue of size 1
+	m := NewJobManager(logrus.New(), 0, 1, time.Hour)
+
+	if _, err := m.Submit("https://example.com/1", false); err != nil {
+		t.Fatal(err)
+	}
+	_, err := m.Submit("https://example.com/2", false)
+	if aerr := asAnalysisError(err); aerr.Status != http.StatusServiceUnavailable || aerr.Code != ErrCodeQueueFull {
+		t.Fatalf("err = %v; want 503 %s", err, ErrCodeQueueFull)
+	}
+}
+
+func TestJobHandlers(t *testing.T) {
+	m := NewJobManager(logrus.New(), 0, 10, time.Hour) // no workers: job stays queued
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/jobs", JobSubmitHandler(logrus.New(), m))
+	mux.Handle("/api/v1/jobs/{id}", JobStatusHandler(m))
+
+	// Submit → 202 + Location
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs", strings.NewReader(`{"url":"https://example.com"}`))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("submit status = %d; want 202; body: %s", rr.Code, rr.Body.String())
+	}
+	var submitted Job
+	if err := json.Unmarshal(rr.Body.Bytes(), &submitted); err != nil {
+		t.Fatal(err)
+	}
+	if loc := rr.Header().Get("Location"); loc != "/api/v1/jobs/"+submitted.ID {
+		t.Errorf("Location = %q; want /api/v1/jobs/%s", loc, submitted.ID)
+	}
+
+	// Poll → 200 with state
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+submitted.ID, nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"state":"queued"`) {
+		t.Fatalf("status = %d, body = %s; want 200 queued", rr.Code, rr.Body.String())
+	}
+
+	// Unknown ID → 404
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/jobs/nope", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("unknown job status = %d; want 404", rr.Code)
+	}
+}
+
+func TestJobManager_ForgetsExpiredJobs(t *testing.T) {
+	// addFinished stores a job that finished an hour ago
+	addFinished := func(m *JobManager, id string) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.jobs[id] = &Job{ID: id, State: JobDone, FinishedAt: time.Now().Add(-time.Hour)}
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		m := NewJobManager(logrus.New(), 0, 1, time.Minute)
+		defer m.Close()
+		addFinished(m, "old")
+		if _, ok := m.Get("old"); ok {
+			t.Errorf("Get returned a job past its retention")
+		}
+	})
+
+	t.Run("janitor", func(t *testing.T) {
+		m := NewJobManager(logrus.New(), 0, 1, 10*time.Millisecond) // Janitor ticks every 10ms
+		defer m.Close()
+		addFinished(m, "old")
+
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			m.mu.Lock()
+			n := len(m.jobs)
+			m.mu.Unlock()
+			if n == 0 {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Errorf("janitor did not purge the expired job")
+	})
+}
@@ -0,0 +1,241 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// httpClient is a reusable HTTP client for link checks with:
+//   - 5-second timeout (don't hang forever)
+//   - No automatic redirect following: checkLink follows them itself,
+//     so it can record every hop and stop at MaxLinkRedirects
+var httpClient = &http.Client{
+	Timeout:   5 * time.Second,
+	Transport: guardedTransport,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse // Hand 301/302 back to checkLink
+	},
+}
+
+// MaxLinkRedirects is how many redirects a link check follows before
+// giving up (set from LINK_MAX_REDIRECTS in main.go). 0 = don't follow.
+var MaxLinkRedirects = 5
+
+// Redirect is one hop in a redirect chain: URL answered Status + Location
+type Redirect struct {
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+}
+
+// linkCancelledMessage is the Error of links left unchecked because the analysis was cancelled
+const linkCancelledMessage = "analysis cancelled"
+
+// headRejectedStatuses are HEAD answers that usually mean "this server
+// doesn't do HEAD", not "this link is broken" → retry with GET.
+var headRejectedStatuses = map[int]bool{
+	http.StatusBadRequest:       true, // 400
+	http.StatusForbidden:        true, // 403
+	http.StatusMethodNotAllowed: true, // 405
+	http.StatusNotImplemented:   true, // 501
+}
+
+// checkLink requests report.URL and fills in health, status, method,
+// redirect chain and latency:
+// 0. Skip it if robots.txt says so; wait our turn on the host
+// 1. Send HEAD (fast, no body); if rejected, retry with a ranged GET
+// 2. On 3xx, record the hop and follow Location (up to MaxLinkRedirects)
+// 3. The final status decides the health: < 400 → ok, otherwise broken
+// Cancelling ctx aborts the check; the link is then left unchecked.
+func checkLink(ctx context.Context, report *LinkReport) {
+	start := time.Now()
+	defer func() { report.LatencyMS = time.Since(start).Milliseconds() }()
+
+	current := report.URL
+	for {
+		// robots.txt + per-host limits come first: a disallowed URL is never requested.
+		// Waiting for the host happens here, before httpClient's timeout starts.
+		release, err := politeRequest(ctx, current)
+		if err != nil {
+			report.Health = LinkUnchecked
+			report.Error = err.Error()
+			if ctx.Err() != nil {
+				report.Error = linkCancelledMessage
+			}
+			return
+		}
+		// Acquire() / Release() limit how many requests run at once across all
+		// analyses (see workerpool.go). The token is taken after the host wait,
+		// so a slow host (long Crawl-delay) can't hold tokens other pages need.
+		Acquire()
+		resp, method, err := probeLink(ctx, current)
+		Release()
+		release()
+
+		if ctx.Err() != nil {
+			// Cancelled mid-request: that says nothing about the link
+			if err == nil {
+				resp.Body.Close()
+			}
+			report.Health = LinkUnchecked
+			report.Error = linkCancelledMessage
+			return
+		}
+
+		var blocked *BlockedAddressError
+		if errors.As(err, &blocked) {
+			// We refused to send it (SSRF guard) → we don't know if it works
+			report.Health = LinkUnchecked
+			report.Error = "blocked: " + blocked.Error()
+			return
+		}
+		if err != nil {
+			// Network error, timeout, DNS failure...
+			report.Health = LinkBroken
+			report.Error = err.Error()
+			return
+		}
+		// We only need status + headers; close to avoid leaks
+		resp.Body.Close()
+
+		report.Method = method
+		report.Status = resp.StatusCode
+
+		// === Redirect? Record the hop and follow it ===
+		// (MaxLinkRedirects = 0: don't follow, the 3xx is the answer)
+		if next, ok := redirectTarget(current, resp); ok && MaxLinkRedirects > 0 {
+			report.Redirects = append(report.Redirects, Redirect{URL: current, Status: resp.StatusCode})
+			if len(report.Redirects) > MaxLinkRedirects {
+				report.Health = LinkBroken
+				report.Error = fmt.Sprintf("too many redirects (limit %d)", MaxLinkRedirects)
+				return
+			}
+			current = next
+			continue
+		}
+
+		// === Final answer ===
+		if len(report.Redirects) > 0 {
+			report.FinalURL = current
+		}
+		if resp.StatusCode >= 400 {
+			// 404, 500, etc. → broken
+			report.Health = LinkBroken
+			report.Error = resp.Status
+		} else {
+			report.Health = LinkOK
+		}
+		return
+	}
+}
+
+// probeLink sends HEAD, and falls back to GET when the server rejects HEAD.
+// The GET asks for a single byte (Range: bytes=0-0) so we don't download
+// whole pages; servers that ignore Range still work, we just close early,
+// and a 416 (Range refused) is retried without it.
+func probeLink(ctx context.Context, target string) (*http.Response, string, error) {
+	req, err := newRequest(ctx, http.MethodHead, target)
+	if err != nil {
+		return nil, http.MethodHead, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, http.MethodHead, err
+	}
+	if !headRejectedStatuses[resp.StatusCode] {
+		return resp, http.MethodHead, nil
+	}
+	resp.Body.Close()
+
+	req, err = newRequest(ctx, http.MethodGet, target)
+	if err != nil {
+		return nil, http.MethodGet, err
+	}
+	req.Header.Set("Range", "bytes=0-0")
+	resp, err = httpClient.Do(req)
+	if err != nil || resp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		return resp, http.MethodGet, err
+	}
+	resp.Body.Close()
+
+	// 416: the server can't do our Range (e.g. empty body), which says nothing
+	// about the link → ask again without it
+	req, err = newRequest(ctx, http.MethodGet, target)
+	if err != nil {
+		return nil, http.MethodGet, err
+	}
+	resp, err = httpClient.Do(req)
+	return resp, http.MethodGet, err
+}
+
+// redirectTarget returns the absolute URL a 3xx response points to.
+// ok is false when resp isn't a usable redirect (no Location, not http(s)).
+func redirectTarget(current string, resp *http.Response) (string, bool) {
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return "", false
+	}
+
+	loc := resp.Header.Get("Location")
+	if loc == "" {
+		return "", false
+	}
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", false
+	}
+	next, err := base.Parse(loc) // Location may be relative
+	if err != nil || (next.Scheme != "http" && next.Scheme != "https") {
+		return "", false
+	}
+	return next.String(), true
+}
+
+// linkSortKeys maps a sort key (as used in ?sort=...) to a "less" function
+var linkSortKeys = map[string]func(a, b LinkReport) bool{
+	"href":    func(a, b LinkReport) bool { return a.Href < b.Href },
+	"url":     func(a, b LinkReport) bool { return a.URL < b.URL },
+	"kind":    func(a, b LinkReport) bool { return a.Kind < b.Kind },
+	"health":  func(a, b LinkReport) bool { return a.Health < b.Health },
+	"status":  func(a, b LinkReport) bool { return a.Status < b.Status },
+	"error":   func(a, b LinkReport) bool { return a.Error < b.Error },
+	"latency": func(a, b LinkReport) bool { return a.LatencyMS < b.LatencyMS },
+	"text":    func(a, b LinkReport) bool { return strings.ToLower(a.Text) < strings.ToLower(b.Text) },
+}
+
+// CheckLinkSortKey reports an unknown sort key, so callers can reject
+// ?sort=... before running an analysis whose links they couldn't sort
+func CheckLinkSortKey(key string) error {
+	if _, ok := linkSortKeys[key]; !ok {
+		return fmt.Errorf("unknown sort key %q", key)
+	}
+	return nil
+}
+
+// SortLinkReports returns a sorted COPY of items (the input is shared with
+// the cache, so we never sort it in place).
+// key is one of: href, url, kind, health, status, error, latency, text.
+// Ties keep document order (stable sort).
+func SortLinkReports(items []LinkReport, key string, desc bool) ([]LinkReport, error) {
+	if err := CheckLinkSortKey(key); err != nil {
+		return nil, err
+	}
+	less := linkSortKeys[key]
+
+	sorted := make([]LinkReport, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if desc {
+			return less(sorted[j], sorted[i])
+		}
+		return less(sorted[i], sorted[j])
+	})
+	return sorted, nil
+}
@@ -0,0 +1,171 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Values of MixedContentItem.Type, following the W3C Mixed Content spec:
+// browsers block active mixed content, and only warn about (or upgrade) passive content
+const (
+	MixedActive  = "active"  // Can change the page: scripts, stylesheets, iframes, objects, form submissions...
+	MixedPassive = "passive" // Only displayed: images, audio, video
+)
+
+// MixedContent lists the resources an https page loads (or submits to) over plain http.
+// It stays empty for http pages: there, everything is insecure anyway.
+type MixedContent struct {
+	Active          int                `json:"active"`           // Items of type active
+	Passive         int                `json:"passive"`          // Items of type passive
+	CredentialForms int                `json:"credential_forms"` // Forms sending passwords or payment data over http
+	Items           []MixedContentItem `json:"items"`            // In document order
+}
+
+// MixedContentItem is one http:// URL on an https page
+type MixedContentItem struct {
+	URL         string `json:"url"`                   // Resolved URL
+	Tag         string `json:"tag"`                   // e.g. "script", "img", "form"
+	Attribute   string `json:"attribute"`             // Where the URL came from: src, href, srcset, action...
+	Type        string `json:"type"`                  // active / passive
+	Path        string `json:"path"`                  // CSS-like path to the element
+	Credentials bool   `json:"credentials,omitempty"` // A form with a password (login, signup...) or payment fields posts here
+}
+
+// credentialPurposes are form purposes (see forms.go) that send secrets
+var credentialPurposes = map[string]bool{
+	FormLogin:         true,
+	FormSignup:        true,
+	FormPasswordReset: true,
+	FormPayment:       true,
+}
+
+// linkRelsActive are <link rel> values that make the browser load (and use) the URL
+var linkRelsActive = map[string]bool{
+	"stylesheet":    true,
+	"preload":       true,
+	"modulepreload": true,
+	"icon":          true,
+	"manifest":      true,
+}
+
+// mixedCollector gathers mixed content while AnalyzePage walks the tree
+type mixedCollector struct {
+	base        *url.URL            // What relative URLs resolve against; nil = don't check
+	credentials map[*html.Node]bool // <form> → does it send credentials? (filled by form())
+	counted     map[*html.Node]bool // Credential forms already in CredentialForms
+	result      MixedContent
+}
+
+// newMixedCollector checks nothing unless pageURL is https.
+// base is the document's <base href> ("" if none), as analyzeLinks uses it.
+func newMixedCollector(pageURL, base string) *mixedCollector {
+	c := &mixedCollector{credentials: make(map[*html.Node]bool), counted: make(map[*html.Node]bool)}
+	if u, err := url.Parse(pageURL); err == nil && u.Scheme == "https" {
+		c.base = u
+		if b, err := u.Parse(base); base != "" && err == nil {
+			c.base = b // <base href="http://..."> makes relative URLs http too
+		}
+	}
+	return c
+}
+
+// form remembers whether a <form> sends credentials; call it before the form's children are visited
+func (c *mixedCollector) form(n *html.Node, report FormReport) {
+	c.credentials[n] = credentialPurposes[report.Purpose] || collectFormFields(n).passwords > 0
+}
+
+// element checks the URL-bearing attributes of one element
+func (c *mixedCollector) element(n *html.Node) {
+	if c.base == nil {
+		return
+	}
+	tag := strings.ToLower(n.Data)
+	switch tag {
+	case "script", "iframe", "frame", "embed", "track":
+		c.check(n, "src", MixedActive, nil)
+	case "object":
+		c.check(n, "data", MixedActive, nil)
+	case "link":
+		for _, rel := range strings.Fields(strings.ToLower(attrValue(n, "rel"))) {
+			if linkRelsActive[rel] {
+				c.check(n, "href", MixedActive, nil)
+				break
+			}
+		}
+
+	case "img", "source":
+		c.check(n, "src", MixedPassive, nil)
+		c.checkSrcset(n)
+	case "audio", "video":
+		c.check(n, "src", MixedPassive, nil)
+		c.check(n, "poster", MixedPassive, nil)
+	case "input":
+		if strings.EqualFold(attrValue(n, "type"), "image") {
+			c.check(n, "src", MixedPassive, nil)
+		}
+		if strings.EqualFold(attrValue(n, "type"), "submit") {
+			c.check(n, "formaction", MixedActive, enclosingForm(n))
+		}
+
+	case "form":
+		c.check(n, "action", MixedActive, n)
+	case "button":
+		c.check(n, "formaction", MixedActive, enclosingForm(n))
+	}
+}
+
+// check records n's attr if it resolves to an http:// URL.
+// form is the <form> submitting there (nil for resources).
+func (c *mixedCollector) check(n *html.Node, attr, kind string, form *html.Node) {
+	for _, a := range n.Attr {
+		if a.Key == attr {
+			c.checkURL(n, attr, a.Val, kind, form)
+		}
+	}
+}
+
+// checkSrcset checks every candidate of srcset="a.jpg 1x, http://b.jpg 2x"
+func (c *mixedCollector) checkSrcset(n *html.Node) {
+	for _, candidate := range strings.Split(attrValue(n, "srcset"), ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			c.checkURL(n, "srcset", fields[0], MixedPassive, nil)
+		}
+	}
+}
+
+// checkURL resolves raw against the base and records it if it's http://
+// (protocol-relative "//cdn..." URLs inherit https, so they're fine)
+func (c *mixedCollector) checkURL(n *html.Node, attr, raw, kind string, form *html.Node) {
+	u, err := c.base.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme != "http" {
+		return
+	}
+	c.result.Items = append(c.result.Items, MixedContentItem{
+		URL:         u.String(),
+		Tag:         strings.ToLower(n.Data),
+		Attribute:   attr,
+		Type:        kind,
+		Path:        cssPath(n),
+		Credentials: c.credentials[form],
+	})
+	if kind == MixedActive {
+		c.result.Active++
+	} else {
+		c.result.Passive++
+	}
+	// A form counts once, even with both an http action and an http formaction
+	if c.credentials[form] && !c.counted[form] {
+		c.counted[form] = true
+		c.result.CredentialForms++
+	}
+}
+
+// build returns the findings (Items is never nil, for the JSON API)
+func (c *mixedCollector) build() MixedContent {
+	if c.result.Items == nil {
+		c.result.Items = []MixedContentItem{}
+	}
+	return c.result
+}
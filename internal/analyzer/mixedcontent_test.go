@@ -0,0 +1,135 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMixedContent(t *testing.T) {
+	const page = `<!DOCTYPE html><html><head>
+<script src="http://cdn.example.com/app.js"></script>
+<script src="//cdn.example.com/ok.js"></script>
+<link rel="stylesheet" href="http://cdn.example.com/site.css">
+<link rel="alternate" href="http://example.com/feed.xml">
+</head><body>
+<img src="/logo.png" srcset="/logo@2x.png 2x, http://img.example.com/logo@3x.png 3x">
+<video poster="http://img.example.com/poster.jpg"><source src="https://media.example.com/v.mp4"></video>
+<iframe src="http://ads.example.com/frame"></iframe>
+<a href="http://example.com/plain-link">links are navigation, not mixed content</a>
+<form action="http://example.com/login" method="post">
+  <input name="username"><input type="password" name="password"><button>Sign in</button>
+</form>
+<form action="http://example.com/search"><input type="search" name="q"></form>
+</body></html>`
+
+	result, err := AnalyzePageWithOptions(strings.NewReader(page), "https://example.com/", Options{SkipLinkCheck: true})
+	if err != nil {
+		t.Fatalf("AnalyzePage: %v", err)
+	}
+	mc := result.MixedContent
+
+	type item struct{ tag, attr, kind string }
+	var got []item
+	for _, i := range mc.Items {
+		got = append(got, item{i.Tag, i.Attribute, i.Type})
+	}
+	want := []item{
+		{"script", "src", MixedActive},
+		{"link", "href", MixedActive},
+		{"img", "srcset", MixedPassive},
+		{"video", "poster", MixedPassive},
+		{"iframe", "src", MixedActive},
+		{"form", "action", MixedActive},
+		{"form", "action", MixedActive},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("items = %v; want %v", got, want)
+	}
+	if mc.Active != 5 || mc.Passive != 2 || mc.CredentialForms != 1 {
+		t.Errorf("active %d, passive %d, credential forms %d; want 5, 2, 1", mc.Active, mc.Passive, mc.CredentialForms)
+	}
+	if len(mc.Items) == 7 && (!mc.Items[5].Credentials || mc.Items[6].Credentials) {
+		t.Errorf("only the login form should be flagged for credentials: %+v", mc.Items[5:])
+	}
+}
+
+func TestMixedContent_OnlyOnHTTPSPages(t *testing.T) {
+	const page = `<script src="http://cdn.example.com/app.js"></script>`
+	for _, pageURL := range []string{"http://example.com/", ""} {
+		result, err := AnalyzePageWithOptions(strings.NewReader(page), pageURL, Options{SkipLinkCheck: true})
+		if err != nil {
+			t.Fatalf("AnalyzePage: %v", err)
+		}
+		if len(result.MixedContent.Items) != 0 {
+			t.Errorf("%q: items = %+v; want none", pageURL, result.MixedContent.Items)
+		}
+	}
+
+	// <base href="http://..."> turns relative URLs into http ones
+	result, err := AnalyzePageWithOptions(strings.NewReader(`<base href="http://example.com/"><img src="a.png">`),
+		"https://example.com/", Options{SkipLinkCheck: true})
+	if err != nil {
+		t.Fatalf("AnalyzePage: %v", err)
+	}
+	if mc := result.MixedContent; mc.Passive != 1 || mc.Items[0].URL != "http://example.com/a.png" {
+		t.Errorf("with http <base>: %+v; want http://example.com/a.png as passive", mc)
+	}
+}
+
+func TestMixedContent_BaseHrefMatchesLinks(t *testing.T) {
+	// The <base> comes after the image: it still applies to the whole document
+	const page = `<img src="logo.png"><base href="http://cdn.example.com/assets/"><a href="about">about</a>`
+	result, err := AnalyzePageWithOptions(strings.NewReader(page), "https://example.com/", Options{SkipLinkCheck: true})
+	if err != nil {
+		t.Fatalf("AnalyzePage: %v", err)
+	}
+	if mc := result.MixedContent; len(mc.Items) != 1 || mc.Items[0].URL != "http://cdn.example.com/assets/logo.png" {
+		t.Errorf("mixed content = %+v; want http://cdn.example.com/assets/logo.png", mc.Items)
+	}
+	link := result.Links.Items[0]
+	if link.URL != "http://cdn.example.com/assets/about" || link.Kind != LinkExternal {
+		t.Errorf("link = %s (%s); want http://cdn.example.com/assets/about, external", link.URL, link.Kind)
+	}
+}
+
+func TestMixedContent_CredentialFormCountedOnce(t *testing.T) {
+	const page = `<form action="http://example.com/login" method="post">
+<input name="user"><input type="password" name="pw">
+<button formaction="http://example.com/login2">Sign in</button>
+</form>`
+	result, err := AnalyzePageWithOptions(strings.NewReader(page), "https://example.com/", Options{SkipLinkCheck: true})
+	if err != nil {
+		t.Fatalf("AnalyzePage: %v", err)
+	}
+	if mc := result.MixedContent; len(mc.Items) != 2 || mc.CredentialForms != 1 {
+		t.Errorf("%d items, %d credential forms; want 2 items from 1 form", len(mc.Items), mc.CredentialForms)
+	}
+}
+
+func TestAnalyzeURL_MixedContentAfterHTTPSRedirect(t *testing.T) {
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<img src="http://img.example.com/a.png">`))
+	}))
+	defer secure.Close()
+	plain := httptest.NewServer(http.RedirectHandler(secure.URL+"/", http.StatusMovedPermanently))
+	defer plain.Close()
+
+	// Trust the test server's self-signed certificate
+	old := PageFetcher.Transport
+	PageFetcher.Transport = secure.Client().Transport
+	defer func() { PageFetcher.Transport = old }()
+
+	// http://site → https://site: the page that was analyzed is the https one
+	result, err := AnalyzeURL(context.Background(), plain.URL+"/", Options{SkipLinkCheck: true})
+	if err != nil {
+		t.Fatalf("AnalyzeURL: %v", err)
+	}
+	if result.MixedContent.Passive != 1 {
+		t.Errorf("mixed content = %+v; want the http image", result.MixedContent)
+	}
+}
@@ -0,0 +1,144 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ValidateURL checks that the user gave us something that looks like
+// an http(s) URL. It returns an *AnalysisError so both the HTML form
+// and the JSON API can report the same problem in their own way.
+func ValidateURL(rawURL string) error {
+	if rawURL == "" {
+		return newAnalysisError(http.StatusBadRequest, ErrCodeMissingURL, "URL is required")
+	}
+	if !urlRegex.MatchString(rawURL) {
+		return newAnalysisError(http.StatusBadRequest, ErrCodeInvalidURL, "Invalid URL format")
+	}
+	return nil
+}
+
+// fetchPage opens a download with PageFetcher's settings and returns the
+// response with its body unread (used for sitemaps, which are streamed).
+// The caller must close the body.
+func fetchPage(ctx context.Context, rawURL string) (*http.Response, error) {
+	resp, _, _, err := PageFetcher.open(ctx, rawURL)
+	return resp, err
+}
+
+// AnalyzeURL is the full pipeline shared by every entry point:
+// 1. Validate the URL
+// 2. Download the page
+// 3. Make sure it answered 200 OK
+// 4. Run AnalyzePage on the body
+// Every failure comes back as an *AnalysisError.
+// opts.Hooks can be used to follow progress (see Hooks).
+func AnalyzeURL(ctx context.Context, rawURL string, opts Options) (*AnalysisResult, error) {
+	if err := ValidateURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	// === Download the webpage (timeout, size limit, redirects: see fetcher.go) ===
+	info, body, err := PageFetcher.Fetch(ctx, rawURL)
+	var blocked *BlockedAddressError
+	if errors.As(err, &blocked) {
+		// Target (or a redirect) points at a private/internal address
+		aerr := newAnalysisError(http.StatusForbidden, ErrCodeBlockedTarget, "Failed to fetch URL: target refused – %v", blocked)
+		aerr.Details = map[string]string{"ip": blocked.IP.String()}
+		return nil, aerr
+	}
+	var skipped *RobotsDisallowedError
+	if errors.As(err, &skipped) {
+		// The site asked crawlers like us to stay away from this URL
+		return nil, newAnalysisError(http.StatusForbidden, ErrCodeRobotsDisallowed, "Failed to fetch URL: %v", skipped)
+	}
+	var tooLarge *BodyTooLargeError
+	if errors.As(err, &tooLarge) {
+		return nil, newAnalysisError(http.StatusBadGateway, ErrCodeBodyTooLarge, "Failed to fetch URL: %v", tooLarge)
+	}
+	if err != nil {
+		// Network error, timeout, bad domain, etc.
+		return nil, newAnalysisError(http.StatusBadGateway, ErrCodeFetchFailed, "Failed to fetch URL: %v", err)
+	}
+	// === Check if page loaded successfully (200 OK) ===
+	if info.StatusCode != http.StatusOK {
+		// 404, 500, 403, etc. → page not available
+		// (also a 3xx the redirect policy didn't let us follow)
+		aerr := newAnalysisError(http.StatusBadGateway, ErrCodeUpstreamStatus, "URL unreachable – HTTP %d %s",
+			info.StatusCode,
+			http.StatusText(info.StatusCode)) // e.g., "404 Not Found"
+		aerr.Details = map[string]string{"upstream_status": fmt.Sprint(info.StatusCode)}
+		if location := info.Headers["Location"]; location != "" {
+			aerr.Details["location"] = location
+		}
+		return nil, aerr
+	}
+
+	// === Make sure it is HTML (or XHTML), not a PDF, image, JSON... (see contenttype.go) ===
+	mediaType, err := checkContentType(info.ContentType, body)
+	var unsupported *UnsupportedContentError
+	if errors.As(err, &unsupported) {
+		aerr := newAnalysisError(http.StatusUnprocessableEntity, ErrCodeUnsupportedType, "Cannot analyze URL: %v", unsupported)
+		aerr.Details = map[string]string{"content_type": info.ContentType, "detected_type": unsupported.Detected}
+		return nil, aerr
+	}
+
+	// === Convert to UTF-8 (Shift_JIS, windows-1251... see charset.go) ===
+	body, encoding := decodeHTML(body, info.ContentType)
+
+	// === Parse the HTML and analyze it ===
+	// Relative URLs resolve against where the redirects landed, like in a browser
+	// (and http://site → https://site must be checked for mixed content)
+	opts.Context = ctx // Link checks stop when the caller gives up
+	result, err := AnalyzePageWithOptions(bytes.NewReader(body), info.FinalURL, opts)
+	if err != nil {
+		// HTML is broken, malformed, etc.
+		return nil, newAnalysisError(http.StatusUnprocessableEntity, ErrCodeParseFailed, "HTML parsing error: %v", err)
+	}
+
+	// Remember where the redirects landed, and how the fetch went
+	if info.FinalURL != rawURL {
+		result.FinalURL = info.FinalURL
+	}
+	result.Fetch = info
+	result.ContentType = mediaType
+	result.Encoding = &encoding
+	security := auditSecurity(info.FinalURL, info.header, info.tlsState, info.certErr, time.Now())
+	result.Security = &security
+
+	return result, nil
+}
+
+// AnalyzeURLCached wraps AnalyzeURL with the result cache:
+// - Look in the cache first (unless refresh is true)
+// - On a miss, run the full pipeline and store the fresh result
+// Only successful analyses are cached – errors are always retried.
+// On a cache hit no hooks fire: the finished result is returned straight away.
+func AnalyzeURLCached(ctx context.Context, rawURL string, refresh bool, opts Options) (*AnalysisResult, CacheInfo, error) {
+	if err := ValidateURL(rawURL); err != nil {
+		return nil, CacheInfo{}, err
+	}
+
+	// === Cache lookup (skipped when the client forces a refresh) ===
+	if !refresh {
+		if cached, info, ok := GetCachedResult(ctx, rawURL); ok {
+			return cached, info, nil
+		}
+	}
+
+	// === Cache miss → do the real work ===
+	result, err := AnalyzeURL(ctx, rawURL, opts)
+	if err != nil {
+		return nil, CacheInfo{}, err
+	}
+
+	// A cancelled run has unchecked links: don't let it stand in for a real result
+	if ctx.Err() == nil {
+		SetCachedResult(ctx, rawURL, result)
+	}
+	return result, CacheInfo{}, nil
+}
@@ -0,0 +1,152 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UserAgent is sent with every request we make (page, links, robots.txt),
+// so site owners can recognise us and address us in robots.txt
+const UserAgent = RobotsAgent + "/1.0"
+
+// Per-host politeness (set from HOST_CONCURRENCY, HOST_DELAY and
+// MAX_CRAWL_DELAY in main.go). The shared link-check pool allows up to
+// MaxWorkers requests at once – without these, a page with 100 internal
+// links would fire 100 requests at one server.
+var (
+	HostConcurrency = 4                // Requests in flight per host
+	HostDelay       time.Duration      // Minimum gap between request starts per host (0 = none)
+	MaxCrawlDelay   = 10 * time.Second // Cap on a robots.txt Crawl-delay we'll honour
+)
+
+// hostGate throttles the requests to one host:
+// slots limits how many run at once, next spaces out their starts
+type hostGate struct {
+	slots chan struct{}
+	mu    sync.Mutex
+	next  time.Time // Earliest start of the next request
+	users int       // politeRequest calls using this gate right now (guarded by hostGates)
+}
+
+// hostGates holds one gate per host ("example.com:443" and "example.com" are the same server,
+// but keeping them apart is harmless and avoids guessing default ports).
+// Gates nobody uses whose delay has passed are dropped every gateSweepInterval,
+// so the map doesn't grow with every host ever contacted.
+var hostGates = struct {
+	sync.Mutex
+	gates     map[string]*hostGate
+	lastSweep time.Time
+}{gates: make(map[string]*hostGate)}
+
+// gateSweepInterval is how often gateFor looks for idle gates to drop
+const gateSweepInterval = time.Minute
+
+// gateFor returns (creating if needed) the gate for host.
+// It counts as a user of the gate until done is called.
+func gateFor(host string) *hostGate {
+	hostGates.Lock()
+	defer hostGates.Unlock()
+
+	if now := time.Now(); now.Sub(hostGates.lastSweep) > gateSweepInterval {
+		sweepGates(now)
+		hostGates.lastSweep = now
+	}
+
+	g, ok := hostGates.gates[host]
+	if !ok {
+		g = &hostGate{slots: make(chan struct{}, max(HostConcurrency, 1))}
+		hostGates.gates[host] = g
+	}
+	g.users++
+	return g
+}
+
+// done ends one gateFor: the gate may be dropped once nobody uses it
+func (g *hostGate) done() {
+	hostGates.Lock()
+	defer hostGates.Unlock()
+	g.users--
+}
+
+// sweepGates drops the gates that have no users and no pending delay:
+// a fresh gate would behave exactly the same. hostGates must be locked.
+func sweepGates(now time.Time) {
+	for host, g := range hostGates.gates {
+		g.mu.Lock()
+		idle := g.users == 0 && !g.next.After(now)
+		g.mu.Unlock()
+		if idle {
+			delete(hostGates.gates, host)
+		}
+	}
+}
+
+// enter waits for a free slot and for the host's delay to pass.
+// Call the returned release func once the response headers are in.
+func (g *hostGate) enter(ctx context.Context, delay time.Duration) (func(), error) {
+	select {
+	case g.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-g.slots }
+
+	// Reserve our start time, then push "next" out by delay
+	g.mu.Lock()
+	start := time.Now()
+	if g.next.After(start) {
+		start = g.next
+	}
+	g.next = start.Add(delay)
+	g.mu.Unlock()
+
+	if wait := time.Until(start); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// politeRequest runs before every page fetch and link probe:
+// 1. robots.txt must allow rawURL (else *RobotsDisallowedError)
+// 2. wait for a slot on the host, spaced by max(HostDelay, Crawl-delay)
+// The wait happens before the request's own timeout starts ticking.
+func politeRequest(ctx context.Context, rawURL string) (release func(), err error) {
+	crawlDelay, err := checkRobots(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return func() {}, nil // the request itself will report the bad URL
+	}
+	delay := max(HostDelay, min(crawlDelay, MaxCrawlDelay))
+	g := gateFor(strings.ToLower(u.Host))
+	leave, err := g.enter(ctx, delay)
+	if err != nil {
+		g.done()
+		return nil, err
+	}
+	return func() { leave(); g.done() }, nil
+}
+
+// newRequest builds an outgoing request carrying our User-Agent
+func newRequest(ctx context.Context, method, rawURL string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	return req, nil
+}
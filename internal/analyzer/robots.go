@@ -0,0 +1,297 @@
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RespectRobots turns robots.txt checks on or off (set from ROBOTS_ENABLED in main.go).
+// When on, URLs that robots.txt disallows for RobotsAgent are skipped, not requested.
+var RespectRobots = true
+
+// RobotsAgent is the product token we look for in "User-agent:" lines.
+// Groups for "*" apply when no group names us.
+const RobotsAgent = "webpage-analyzer"
+
+// RobotsCacheTTL is how long a host's robots.txt is reused before fetching it again
+var RobotsCacheTTL = time.Hour
+
+// robotsRetryAfter is how long a robots.txt server error (5xx) blocks a host
+// before we try again (capped by RobotsCacheTTL)
+var robotsRetryAfter = time.Minute
+
+// maxRobotsBytes caps how much of a robots.txt we read (RFC 9309 asks for at least 500 KiB)
+const maxRobotsBytes = 500 << 10
+
+// robotsClient fetches robots.txt files. It follows redirects (robots.txt
+// often moves to https://www.) and shares the SSRF-guarded transport.
+var robotsClient = &http.Client{
+	Timeout:   5 * time.Second,
+	Transport: guardedTransport,
+}
+
+// RobotsDisallowedError is returned instead of sending a request that
+// the target's robots.txt disallows
+type RobotsDisallowedError struct {
+	URL string
+}
+
+// robotsSkipMessage is the error text of links skipped because of robots.txt
+const robotsSkipMessage = "skipped by robots.txt"
+
+func (e *RobotsDisallowedError) Error() string {
+	return robotsSkipMessage
+}
+
+// robotsRules is the part of one robots.txt that applies to us
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration // 0 = none given
+	sitemaps   []string      // "Sitemap:" lines (they apply to every agent)
+}
+
+// robotsRule is one Allow/Disallow line. path may contain "*" (any run of
+// characters) and end with "$" (end of URL).
+type robotsRule struct {
+	allow bool
+	path  string
+}
+
+// allowAll is what we use when there is no robots.txt (or we can't read it)
+var allowAll = &robotsRules{}
+
+// disallowAll is what we use while the server fails to serve robots.txt:
+// RFC 9309 says an unreachable robots.txt means "assume complete disallow"
+var disallowAll = &robotsRules{rules: []robotsRule{{allow: false, path: "/"}}}
+
+// allowed decides for a path (+ "?query"): the longest matching rule wins,
+// and Allow wins a tie. No matching rule → allowed.
+func (r *robotsRules) allowed(path string) bool {
+	best, allow := -1, true
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.path, path) {
+			continue
+		}
+		if n := len(rule.path); n > best || (n == best && rule.allow) {
+			best, allow = n, rule.allow
+		}
+	}
+	return allow
+}
+
+// robotsMatch reports whether path matches a robots.txt pattern:
+// a prefix match, where "*" matches anything and a trailing "$" anchors the end
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			// Last piece of an anchored pattern must end the path
+			return strings.HasSuffix(path[pos:], part)
+		}
+		idx := strings.Index(path[pos:], part)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+	return !anchored || pos == len(path)
+}
+
+// robotsGroup is one "User-agent: ..." block while parsing
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// parseRobots reads a robots.txt and keeps the rules for agent:
+// every group naming agent, or else every "*" group, merged together.
+// Sitemap lines are kept whatever the agent.
+func parseRobots(r io.Reader, agent string) *robotsRules {
+	var groups []*robotsGroup
+	var cur *robotsGroup
+	var sitemaps []string
+	inAgentLines := false // consecutive User-agent lines share one group
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i] // strip comments
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if cur == nil || !inAgentLines {
+				cur = &robotsGroup{}
+				groups = append(groups, cur)
+			}
+			cur.agents = append(cur.agents, strings.ToLower(value))
+			inAgentLines = true
+		case "allow", "disallow":
+			inAgentLines = false
+			if cur == nil || value == "" { // "Disallow:" (empty) = allow everything
+				continue
+			}
+			cur.rules = append(cur.rules, robotsRule{allow: key == "allow", path: value})
+		case "crawl-delay":
+			inAgentLines = false
+			if secs, err := strconv.ParseFloat(value, 64); err == nil && cur != nil && secs > 0 {
+				cur.crawlDelay = time.Duration(secs * float64(time.Second))
+			}
+		case "sitemap":
+			// Not part of any group: doesn't end the User-agent lines either
+			if value != "" {
+				sitemaps = append(sitemaps, value)
+			}
+		}
+	}
+
+	// Our own groups win; "*" only applies when nobody names us
+	pick := func(match func(a string) bool) *robotsRules {
+		var rules *robotsRules
+		for _, g := range groups {
+			for _, a := range g.agents {
+				if match(a) {
+					if rules == nil {
+						rules = &robotsRules{}
+					}
+					rules.rules = append(rules.rules, g.rules...)
+					rules.crawlDelay = max(rules.crawlDelay, g.crawlDelay)
+					break
+				}
+			}
+		}
+		return rules
+	}
+	agent = strings.ToLower(agent)
+	rules := pick(func(a string) bool { return a == agent })
+	if rules == nil {
+		rules = pick(func(a string) bool { return a == "*" })
+	}
+	if rules == nil {
+		rules = &robotsRules{} // nothing for us: allow all
+	}
+	rules.sitemaps = sitemaps
+	return rules
+}
+
+// robotsEntry is one cached robots.txt; ready is closed once rules is set,
+// so concurrent lookups for the same host wait for a single download
+type robotsEntry struct {
+	ready   chan struct{}
+	rules   *robotsRules
+	expires time.Time
+}
+
+// robotsCache holds one entry per origin ("https://example.com")
+var robotsCache = struct {
+	sync.Mutex
+	entries map[string]*robotsEntry
+}{entries: make(map[string]*robotsEntry)}
+
+// robotsFor returns the rules for u's origin, downloading robots.txt
+// the first time (and again once RobotsCacheTTL has passed)
+func robotsFor(ctx context.Context, u *url.URL) *robotsRules {
+	origin := u.Scheme + "://" + strings.ToLower(u.Host)
+
+	robotsCache.Lock()
+	entry, ok := robotsCache.entries[origin]
+	if !ok || time.Now().After(entry.expires) {
+		entry = &robotsEntry{ready: make(chan struct{}), expires: time.Now().Add(RobotsCacheTTL)}
+		robotsCache.entries[origin] = entry
+		robotsCache.Unlock()
+
+		entry.rules = fetchRobots(origin)
+		if entry.rules == disallowAll && robotsRetryAfter < RobotsCacheTTL {
+			// A server error is usually temporary: ask again soon
+			robotsCache.Lock()
+			entry.expires = time.Now().Add(robotsRetryAfter)
+			robotsCache.Unlock()
+		}
+		close(entry.ready)
+		return entry.rules
+	}
+	robotsCache.Unlock()
+
+	select {
+	case <-entry.ready:
+		return entry.rules
+	case <-ctx.Done():
+		return allowAll // caller is giving up anyway
+	}
+}
+
+// fetchRobots downloads and parses origin's robots.txt.
+// - 2xx   → parse it
+// - 4xx   → no robots.txt: everything is allowed
+// - 5xx   → everything is disallowed, until robotsRetryAfter has passed
+// - error → allowed: the real request will report the problem
+func fetchRobots(origin string) *robotsRules {
+	// Not tied to one caller's context: other requests may be waiting on it
+	req, err := newRequest(context.Background(), http.MethodGet, origin+"/robots.txt")
+	if err != nil {
+		return allowAll
+	}
+	resp, err := robotsClient.Do(req)
+	if err != nil {
+		return allowAll
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return disallowAll
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return allowAll
+	}
+	return parseRobots(io.LimitReader(resp.Body, maxRobotsBytes), RobotsAgent)
+}
+
+// checkRobots returns a *RobotsDisallowedError if robots.txt forbids rawURL,
+// plus the Crawl-delay the host asked for
+func checkRobots(ctx context.Context, rawURL string) (time.Duration, error) {
+	if !RespectRobots {
+		return 0, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return 0, nil // not our problem here: the request itself will fail
+	}
+	if u.Path == "/robots.txt" {
+		return 0, nil
+	}
+
+	rules := robotsFor(ctx, u)
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	if !rules.allowed(path) {
+		return rules.crawlDelay, &RobotsDisallowedError{URL: rawURL}
+	}
+	return rules.crawlDelay, nil
+}
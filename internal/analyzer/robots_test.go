@@ -0,0 +1,249 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// withRobots turns robots.txt checks on for one test (TestMain turns them off)
+func withRobots(t *testing.T) {
+	t.Helper()
+	old := RespectRobots
+	RespectRobots = true
+	t.Cleanup(func() { RespectRobots = old })
+}
+
+func TestParseRobots(t *testing.T) {
+	const robots = `
+# Everyone else
+User-agent: *
+Disallow: /
+
+User-agent: Googlebot
+User-agent: webpage-analyzer
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+Disallow: /search?
+Crawl-delay: 1.5
+
+Sitemap: https://example.com/sitemap.xml
+`
+	rules := parseRobots(strings.NewReader(robots), RobotsAgent)
+
+	if rules.crawlDelay != 1500*time.Millisecond {
+		t.Errorf("crawlDelay = %v; want 1.5s", rules.crawlDelay)
+	}
+	if want := []string{"https://example.com/sitemap.xml"}; !reflect.DeepEqual(rules.sitemaps, want) {
+		t.Errorf("sitemaps = %v; want %v", rules.sitemaps, want)
+	}
+
+	cases := []struct {
+		path    string
+		allowed bool
+	}{
+		{"/", true}, // "*" group doesn't apply: we have our own
+		{"/about", true},
+		{"/private", false}, // prefix match
+		{"/private/secret", false},
+		{"/private/public/page", true}, // longer Allow wins
+		{"/docs/guide.pdf", false},     // wildcard + end anchor
+		{"/docs/guide.pdf?x=1", true},  // "$" means the URL ends there
+		{"/search?q=go", false},        // query string is part of the match
+		{"/searching", true},
+	}
+	for _, tc := range cases {
+		if got := rules.allowed(tc.path); got != tc.allowed {
+			t.Errorf("allowed(%q) = %v; want %v", tc.path, got, tc.allowed)
+		}
+	}
+
+	// Another agent falls back to the "*" group
+	other := parseRobots(strings.NewReader(robots), "some-other-bot")
+	if other.allowed("/about") {
+		t.Error(`"*" group should disallow everything for other agents`)
+	}
+
+	// Empty Disallow = allow all; no robots.txt rules at all = allow all
+	if !parseRobots(strings.NewReader("User-agent: *\nDisallow:\n"), RobotsAgent).allowed("/x") {
+		t.Error("empty Disallow should allow everything")
+	}
+}
+
+func TestRobots_PageAndLinks(t *testing.T) {
+	withRobots(t)
+
+	var robotsFetches atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			robotsFetches.Add(1)
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+		case "/":
+			_, _ = w.Write([]byte(`<a href="/ok">ok</a><a href="/private/admin">admin</a>`))
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer ts.Close()
+
+	// === Disallowed links are reported, not requested ===
+	result, err := AnalyzeURL(context.Background(), ts.URL+"/", Options{})
+	if err != nil {
+		t.Fatalf("AnalyzeURL: %v", err)
+	}
+	if result.Links.OK != 1 || result.Links.Unchecked != 1 || result.Links.SkippedByRobots != 1 {
+		t.Errorf("links = %+v; want 1 ok, 1 skipped by robots", result.Links)
+	}
+	if item := result.Links.Items[1]; item.Health != LinkUnchecked || item.Error != "skipped by robots.txt" || item.Status != 0 {
+		t.Errorf("private link = %+v; want unchecked, skipped by robots.txt, never requested", item)
+	}
+
+	// === Disallowed page → 403 robots_disallowed ===
+	_, err = AnalyzeURL(context.Background(), ts.URL+"/private/page", Options{})
+	var aerr *AnalysisError
+	if !errors.As(err, &aerr) || aerr.Status != http.StatusForbidden || aerr.Code != ErrCodeRobotsDisallowed {
+		t.Fatalf("err = %v; want 403 %s", err, ErrCodeRobotsDisallowed)
+	}
+
+	// robots.txt was downloaded once and cached for everything above
+	if n := robotsFetches.Load(); n != 1 {
+		t.Errorf("robots.txt fetched %d times; want 1", n)
+	}
+}
+
+func TestRobots_ServerErrorDisallowsUntilRetry(t *testing.T) {
+	withRobots(t)
+
+	var robotsStatus atomic.Int32
+	robotsStatus.Store(http.StatusServiceUnavailable)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(int(robotsStatus.Load()))
+			return
+		}
+		_, _ = w.Write([]byte("<title>ok</title>"))
+	}))
+	defer ts.Close()
+
+	// === 5xx on robots.txt → the whole host is off limits ===
+	_, err := AnalyzeURL(context.Background(), ts.URL+"/", Options{})
+	var aerr *AnalysisError
+	if !errors.As(err, &aerr) || aerr.Code != ErrCodeRobotsDisallowed {
+		t.Fatalf("err = %v; want %s while robots.txt answers 503", err, ErrCodeRobotsDisallowed)
+	}
+
+	// === The server recovers: still blocked until the retry window ends ===
+	robotsStatus.Store(http.StatusNotFound)
+	if _, err := AnalyzeURL(context.Background(), ts.URL+"/", Options{}); !errors.As(err, &aerr) {
+		t.Fatalf("err = %v; want the 503 to stay cached for robotsRetryAfter", err)
+	}
+
+	// The error is cached for robotsRetryAfter, not the full RobotsCacheTTL
+	u, _ := url.Parse(ts.URL)
+	robotsCache.Lock()
+	entry := robotsCache.entries[u.Scheme+"://"+u.Host]
+	if left := time.Until(entry.expires); left > robotsRetryAfter {
+		t.Errorf("server error cached for %v; want at most %v", left, robotsRetryAfter)
+	}
+	entry.expires = time.Now() // As if robotsRetryAfter had passed
+	robotsCache.Unlock()
+
+	if _, err := AnalyzeURL(context.Background(), ts.URL+"/", Options{}); err != nil {
+		t.Fatalf("AnalyzeURL after the retry window: %v; want allowed (robots.txt is now 404)", err)
+	}
+}
+
+func TestHostGate(t *testing.T) {
+	oldConc := HostConcurrency
+	defer func() { HostConcurrency = oldConc }()
+	HostConcurrency = 1
+
+	// === Concurrency: the second request waits for the first ===
+	g := gateFor("gate-test.example")
+	release, err := g.enter(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("enter: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := g.enter(ctx, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second enter = %v; want to block until the deadline", err)
+	}
+	release()
+
+	// === Delay: starts are spaced out ===
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := g.enter(context.Background(), 40*time.Millisecond)
+		if err != nil {
+			t.Fatalf("enter: %v", err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("3 requests with a 40ms delay took %v; want >= 80ms", elapsed)
+	}
+}
+
+func TestAnalyzeLinks_NoTokenWhileWaitingForHost(t *testing.T) {
+	oldConc := HostConcurrency
+	defer func() { HostConcurrency = oldConc }()
+	HostConcurrency = 1
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	// Keep the host busy, so the link check has to wait for it
+	u, _ := url.Parse(ts.URL)
+	g := gateFor(u.Host)
+	defer g.done()
+	release, err := g.enter(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("enter: %v", err)
+	}
+
+	initWorkerPool()
+	done := make(chan struct{})
+	go func() {
+		analyzeLinks([]rawLink{{Href: "/"}}, ts.URL, "", Options{})
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if free := len(workerPool.tokens); free != cap(workerPool.tokens) {
+		t.Errorf("%d of %d worker tokens free while waiting for the host; want all", free, cap(workerPool.tokens))
+	}
+	release()
+	<-done
+}
+
+func TestSweepGates(t *testing.T) {
+	idle := gateFor("sweep-idle.example")
+	idle.done()
+	busy := gateFor("sweep-busy.example") // Still in use
+	defer busy.done()
+	waiting := gateFor("sweep-delay.example")
+	waiting.done()
+	waiting.next = time.Now().Add(time.Hour) // Its delay hasn't passed
+
+	hostGates.Lock()
+	sweepGates(time.Now())
+	_, idleKept := hostGates.gates["sweep-idle.example"]
+	_, busyKept := hostGates.gates["sweep-busy.example"]
+	_, waitingKept := hostGates.gates["sweep-delay.example"]
+	delete(hostGates.gates, "sweep-delay.example")
+	hostGates.Unlock()
+
+	if idleKept || !busyKept || !waitingKept {
+		t.Errorf("kept idle=%v busy=%v waiting=%v; want false, true, true", idleKept, busyKept, waitingKept)
+	}
+}
@@ -0,0 +1,403 @@
+package analyzer
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Security is the launch-review audit of the page's response: security
+// headers, cookies and TLS, each check graded, plus an overall grade
+type Security struct {
+	Grade   string          `json:"grade"`         // A (best) to F
+	Score   int             `json:"score"`         // 0–100: 100 minus the failed/warned checks
+	Checks  []SecurityCheck `json:"checks"`        // Every check, in a fixed order
+	CSP     *CSP            `json:"csp,omitempty"` // Parsed Content-Security-Policy (nil if none)
+	Cookies []CookieReport  `json:"cookies"`       // One per Set-Cookie
+	TLS     *TLSReport      `json:"tls,omitempty"` // nil for plain http
+}
+
+// Values of SecurityCheck.Status
+const (
+	CheckPass = "pass"
+	CheckWarn = "warn" // Weak or missing, but not critical
+	CheckFail = "fail"
+	CheckInfo = "info" // Worth knowing, doesn't count
+)
+
+// Score lost per check (see Security.Score)
+const (
+	failPenalty = 15
+	warnPenalty = 5
+)
+
+// SecurityCheck is one graded finding
+type SecurityCheck struct {
+	Name    string `json:"name"`            // Header or area, e.g. "Strict-Transport-Security", "TLS version"
+	Status  string `json:"status"`          // pass / warn / fail / info
+	Value   string `json:"value,omitempty"` // What we found
+	Message string `json:"message"`         // Why it got this status
+}
+
+// CSP is a parsed Content-Security-Policy
+type CSP struct {
+	Directives map[string][]string `json:"directives"`  // e.g. "script-src" → ["'self'", "cdn.example.com"]
+	ReportOnly bool                `json:"report_only"` // Only Content-Security-Policy-Report-Only was sent
+}
+
+// CookieReport is one Set-Cookie and its security flags
+type CookieReport struct {
+	Name     string   `json:"name"`
+	Secure   bool     `json:"secure"`
+	HttpOnly bool     `json:"http_only"`
+	SameSite string   `json:"same_site"` // strict / lax / none, or "" if not set
+	Issues   []string `json:"issues,omitempty"`
+}
+
+// TLSReport describes the connection and certificate of an https page.
+// The page fetch accepts any certificate (see inspectingTransport), so an
+// untrusted, expired or wrong-host certificate is reported here instead.
+type TLSReport struct {
+	Version     string    `json:"version"`      // e.g. "TLS 1.3"
+	Cipher      string    `json:"cipher"`       // e.g. "TLS_AES_128_GCM_SHA256"
+	Issuer      string    `json:"issuer"`       // Certificate issuer (organisation or common name)
+	Subject     string    `json:"subject"`      // Certificate common name
+	DNSNames    []string  `json:"dns_names"`    // Subject alternative names
+	NotAfter    time.Time `json:"not_after"`    // Expiry
+	DaysLeft    int       `json:"days_left"`    // Until expiry (negative once expired)
+	HostMatches bool      `json:"host_matches"` // The page's host is one of the certificate's names
+	Trusted     bool      `json:"trusted"`      // The chain leads to a trusted root, every certificate in its validity period
+}
+
+// hstsMinMaxAge is the shortest HSTS max-age we don't warn about (180 days,
+// a common minimum; the preload list wants a year)
+const hstsMinMaxAge = 180 * 24 * 60 * 60
+
+// certWarnDays / certFailDays: how close to expiry a certificate gets a warning / a failure
+const (
+	certWarnDays = 30
+	certFailDays = 7
+)
+
+// auditSecurity grades the final response of a page fetch.
+// header and state come from that response; state is nil for plain http.
+// certErr is why the certificate chain didn't verify (nil = it did).
+func auditSecurity(pageURL string, header http.Header, state *tls.ConnectionState, certErr error, now time.Time) Security {
+	s := Security{Checks: []SecurityCheck{}, Cookies: []CookieReport{}}
+	add := func(name, status, value, message string) {
+		s.Checks = append(s.Checks, SecurityCheck{Name: name, Status: status, Value: value, Message: message})
+	}
+	u, _ := url.Parse(pageURL)
+	https := u != nil && u.Scheme == "https"
+
+	// === HTTPS + HSTS ===
+	if !https {
+		add("HTTPS", CheckFail, "", "page is served over plain http")
+	} else {
+		add("HTTPS", CheckPass, "", "page is served over https")
+	}
+	hsts := header.Get("Strict-Transport-Security")
+	switch {
+	case !https:
+		add("Strict-Transport-Security", CheckInfo, hsts, "only applies to https pages")
+	case hsts == "":
+		add("Strict-Transport-Security", CheckFail, "", "missing: browsers may still try http first")
+	default:
+		maxAge, ok := hstsMaxAge(hsts)
+		switch {
+		case !ok:
+			add("Strict-Transport-Security", CheckFail, hsts, "no valid max-age")
+		case maxAge < hstsMinMaxAge:
+			add("Strict-Transport-Security", CheckWarn, hsts, fmt.Sprintf("max-age is %d days (recommended at least 180)", maxAge/86400))
+		default:
+			add("Strict-Transport-Security", CheckPass, hsts, "enabled")
+		}
+	}
+
+	// === Content-Security-Policy ===
+	s.CSP = parseCSP(header)
+	if s.CSP == nil {
+		add("Content-Security-Policy", CheckFail, "", "missing: nothing limits where scripts can load from")
+	} else {
+		for _, c := range cspChecks(s.CSP) {
+			add("Content-Security-Policy", c.Status, c.Value, c.Message)
+		}
+	}
+
+	// === Framing, sniffing, referrer, permissions ===
+	xfo := strings.TrimSpace(header.Get("X-Frame-Options"))
+	switch upper := strings.ToUpper(xfo); {
+	case upper == "DENY" || upper == "SAMEORIGIN":
+		add("X-Frame-Options", CheckPass, xfo, "framing restricted")
+	case s.CSP != nil && !s.CSP.ReportOnly && s.CSP.Directives["frame-ancestors"] != nil:
+		add("X-Frame-Options", CheckPass, xfo, "framing controlled by CSP frame-ancestors")
+	case xfo == "":
+		add("X-Frame-Options", CheckFail, "", "missing: the page can be framed (clickjacking)")
+	default:
+		add("X-Frame-Options", CheckWarn, xfo, "unsupported value (use DENY or SAMEORIGIN)")
+	}
+
+	xcto := strings.TrimSpace(header.Get("X-Content-Type-Options"))
+	if strings.EqualFold(xcto, "nosniff") {
+		add("X-Content-Type-Options", CheckPass, xcto, "MIME sniffing disabled")
+	} else {
+		add("X-Content-Type-Options", CheckFail, xcto, "should be nosniff")
+	}
+
+	referrer := strings.TrimSpace(header.Get("Referrer-Policy"))
+	switch policy := lastToken(referrer); policy {
+	case "":
+		add("Referrer-Policy", CheckWarn, "", "missing: browsers fall back to their default")
+	case "unsafe-url", "no-referrer-when-downgrade":
+		add("Referrer-Policy", CheckWarn, referrer, policy+" leaks full URLs to other sites")
+	default:
+		add("Referrer-Policy", CheckPass, referrer, "set")
+	}
+
+	if pp := header.Get("Permissions-Policy"); pp != "" {
+		add("Permissions-Policy", CheckPass, pp, "set")
+	} else {
+		add("Permissions-Policy", CheckWarn, "", "missing: camera, geolocation... are left at browser defaults")
+	}
+
+	// === Cookies ===
+	for _, c := range (&http.Response{Header: header}).Cookies() {
+		report := auditCookie(c, https)
+		s.Cookies = append(s.Cookies, report)
+		status := CheckPass
+		switch {
+		case c.SameSite == http.SameSiteNoneMode && !c.Secure:
+			status = CheckFail
+		case len(report.Issues) > 0:
+			status = CheckWarn
+		}
+		message := "Secure, HttpOnly and SameSite set"
+		if len(report.Issues) > 0 {
+			message = strings.Join(report.Issues, "; ")
+		}
+		add("Cookie "+c.Name, status, "", message)
+	}
+
+	// === TLS ===
+	if state != nil {
+		s.TLS = tlsReport(state, u.Hostname(), certErr, now)
+		version := state.Version
+		switch {
+		case version < tls.VersionTLS12:
+			add("TLS version", CheckFail, s.TLS.Version, "TLS 1.0/1.1 are deprecated")
+		default:
+			add("TLS version", CheckPass, s.TLS.Version, "modern TLS")
+		}
+		insecure := slices.ContainsFunc(tls.InsecureCipherSuites(), func(c *tls.CipherSuite) bool { return c.ID == state.CipherSuite })
+		if insecure {
+			add("TLS cipher", CheckFail, s.TLS.Cipher, "cipher suite is considered insecure")
+		} else {
+			add("TLS cipher", CheckPass, s.TLS.Cipher, "secure cipher suite")
+		}
+		switch {
+		case len(state.PeerCertificates) == 0:
+			add("Certificate", CheckFail, "", "no certificate presented")
+		case !s.TLS.HostMatches:
+			add("Certificate", CheckFail, s.TLS.Subject, "does not cover "+u.Hostname())
+		case now.After(s.TLS.NotAfter):
+			add("Certificate", CheckFail, s.TLS.NotAfter.Format(time.DateOnly), "expired")
+		case !s.TLS.Trusted:
+			add("Certificate", CheckFail, s.TLS.Issuer, "not trusted: "+strings.TrimPrefix(certErr.Error(), "x509: "))
+		case s.TLS.DaysLeft < certFailDays:
+			add("Certificate", CheckFail, s.TLS.NotAfter.Format(time.DateOnly), fmt.Sprintf("expires in %d days", s.TLS.DaysLeft))
+		case s.TLS.DaysLeft < certWarnDays:
+			add("Certificate", CheckWarn, s.TLS.NotAfter.Format(time.DateOnly), fmt.Sprintf("expires in %d days", s.TLS.DaysLeft))
+		default:
+			add("Certificate", CheckPass, s.TLS.NotAfter.Format(time.DateOnly),
+				fmt.Sprintf("issued by %s, valid for %d more days", s.TLS.Issuer, s.TLS.DaysLeft))
+		}
+	}
+
+	// === Grade ===
+	s.Score = 100
+	for _, c := range s.Checks {
+		switch c.Status {
+		case CheckFail:
+			s.Score -= failPenalty
+		case CheckWarn:
+			s.Score -= warnPenalty
+		}
+	}
+	s.Score = max(s.Score, 0)
+	s.Grade = securityGrade(s.Score)
+	return s
+}
+
+// securityGrade turns a score into a letter
+func securityGrade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 75:
+		return "B"
+	case score >= 60:
+		return "C"
+	case score >= 45:
+		return "D"
+	}
+	return "F"
+}
+
+// hstsMaxAge reads max-age from a Strict-Transport-Security value
+func hstsMaxAge(v string) (int, bool) {
+	for _, part := range strings.Split(v, ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if strings.EqualFold(strings.TrimSpace(name), "max-age") {
+			n, err := strconv.Atoi(strings.Trim(strings.TrimSpace(value), `"`))
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
+
+// lastToken returns the last non-empty comma-separated token, lowercased
+// (Referrer-Policy may list fallbacks; browsers use the last one they know)
+func lastToken(v string) string {
+	tokens := strings.Split(v, ",")
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if t := strings.ToLower(strings.TrimSpace(tokens[i])); t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+// parseCSP reads Content-Security-Policy (or, failing that, the Report-Only variant).
+// With several policies, the first occurrence of each directive is kept.
+func parseCSP(header http.Header) *CSP {
+	csp := &CSP{Directives: make(map[string][]string)}
+	values := header.Values("Content-Security-Policy")
+	if len(values) == 0 {
+		values = header.Values("Content-Security-Policy-Report-Only")
+		csp.ReportOnly = true
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	for _, policy := range values {
+		for _, directive := range strings.Split(policy, ";") {
+			fields := strings.Fields(directive)
+			if len(fields) == 0 {
+				continue
+			}
+			name := strings.ToLower(fields[0])
+			if _, seen := csp.Directives[name]; !seen {
+				csp.Directives[name] = fields[1:]
+			}
+		}
+	}
+	return csp
+}
+
+// cspChecks flags the weak spots of a policy
+func cspChecks(csp *CSP) []SecurityCheck {
+	var checks []SecurityCheck
+	warn := func(value, message string) {
+		checks = append(checks, SecurityCheck{Status: CheckWarn, Value: value, Message: message})
+	}
+	if csp.ReportOnly {
+		warn("", "only Content-Security-Policy-Report-Only is sent: nothing is enforced")
+	}
+
+	// script-src falls back to default-src
+	scripts, directive := csp.Directives["script-src"], "script-src"
+	if scripts == nil {
+		scripts, directive = csp.Directives["default-src"], "default-src"
+	}
+	if scripts == nil {
+		warn("", "no script-src or default-src: scripts may load from anywhere")
+	}
+
+	// With a nonce or hash, CSP2+ browsers ignore 'unsafe-inline'
+	nonceOrHash := slices.ContainsFunc(scripts, func(s string) bool {
+		s = strings.ToLower(s)
+		return strings.HasPrefix(s, "'nonce-") || strings.HasPrefix(s, "'sha256-") ||
+			strings.HasPrefix(s, "'sha384-") || strings.HasPrefix(s, "'sha512-")
+	})
+	for _, src := range scripts {
+		switch strings.ToLower(src) {
+		case "'unsafe-inline'":
+			if !nonceOrHash {
+				warn(directive+" "+src, "allows inline scripts, which defeats most XSS protection")
+			}
+		case "'unsafe-eval'":
+			warn(directive+" "+src, "allows eval()")
+		case "*", "http:", "https:", "data:":
+			warn(directive+" "+src, "allows scripts from any host")
+		}
+	}
+
+	if csp.Directives["object-src"] == nil && !slices.Contains(csp.Directives["default-src"], "'none'") {
+		warn("", "no object-src: plugins (<object>, <embed>) are not restricted; use object-src 'none'")
+	}
+	if csp.Directives["base-uri"] == nil {
+		warn("", "no base-uri: injected <base> tags can redirect relative URLs")
+	}
+	if len(checks) == 0 {
+		checks = append(checks, SecurityCheck{Status: CheckPass, Message: "no weak directives found"})
+	}
+	return checks
+}
+
+// auditCookie lists what's missing from one cookie's flags
+func auditCookie(c *http.Cookie, https bool) CookieReport {
+	r := CookieReport{Name: c.Name, Secure: c.Secure, HttpOnly: c.HttpOnly}
+	switch c.SameSite {
+	case http.SameSiteStrictMode:
+		r.SameSite = "strict"
+	case http.SameSiteLaxMode:
+		r.SameSite = "lax"
+	case http.SameSiteNoneMode:
+		r.SameSite = "none"
+	}
+
+	if !c.Secure && https {
+		r.Issues = append(r.Issues, "no Secure flag")
+	}
+	if !c.HttpOnly {
+		r.Issues = append(r.Issues, "no HttpOnly flag (readable from JavaScript)")
+	}
+	switch {
+	case r.SameSite == "":
+		r.Issues = append(r.Issues, "no SameSite attribute")
+	case r.SameSite == "none" && !c.Secure:
+		r.Issues = append(r.Issues, "SameSite=None without Secure is rejected by browsers")
+	}
+	return r
+}
+
+// tlsReport describes the negotiated connection and the leaf certificate
+func tlsReport(state *tls.ConnectionState, host string, certErr error, now time.Time) *TLSReport {
+	r := &TLSReport{
+		Version:  tls.VersionName(state.Version),
+		Cipher:   tls.CipherSuiteName(state.CipherSuite),
+		DNSNames: []string{},
+	}
+	if len(state.PeerCertificates) == 0 {
+		return r
+	}
+	leaf := state.PeerCertificates[0]
+	r.Issuer = leaf.Issuer.CommonName
+	if len(leaf.Issuer.Organization) > 0 {
+		r.Issuer = leaf.Issuer.Organization[0]
+	}
+	r.Subject = leaf.Subject.CommonName
+	if leaf.DNSNames != nil {
+		r.DNSNames = leaf.DNSNames
+	}
+	r.NotAfter = leaf.NotAfter
+	r.DaysLeft = int(leaf.NotAfter.Sub(now).Hours() / 24)
+	r.HostMatches = leaf.VerifyHostname(host) == nil
+	r.Trusted = certErr == nil
+	return r
+}
@@ -0,0 +1,228 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// statuses collects the status of every check, by name (the last one wins for repeated names)
+func statuses(s Security) map[string]string {
+	m := make(map[string]string)
+	for _, c := range s.Checks {
+		m[c.Name] = c.Status
+	}
+	return m
+}
+
+func TestAuditSecurity_Headers(t *testing.T) {
+	hardened := http.Header{
+		"Strict-Transport-Security": {"max-age=31536000; includeSubDomains"},
+		"Content-Security-Policy":   {"default-src 'self'; script-src 'self' 'nonce-abc' 'unsafe-inline'; object-src 'none'; base-uri 'none'; frame-ancestors 'none'"},
+		"X-Content-Type-Options":    {"nosniff"},
+		"Referrer-Policy":           {"no-referrer, strict-origin-when-cross-origin"},
+		"Permissions-Policy":        {"geolocation=()"},
+		"Set-Cookie":                {"session=1; Secure; HttpOnly; SameSite=Lax"},
+	}
+	s := auditSecurity("https://example.com/", hardened, nil, nil, time.Now())
+	if s.Score != 100 || s.Grade != "A" {
+		t.Errorf("hardened: score %d grade %s; want 100 A (checks %+v)", s.Score, s.Grade, s.Checks)
+	}
+	if got := statuses(s)["X-Frame-Options"]; got != CheckPass {
+		t.Errorf("X-Frame-Options = %s; want pass thanks to frame-ancestors", got)
+	}
+
+	bare := http.Header{"Set-Cookie": {"prefs=dark", "track=1; SameSite=None"}}
+	s = auditSecurity("http://example.com/", bare, nil, nil, time.Now())
+	want := map[string]string{
+		"HTTPS":                     CheckFail,
+		"Strict-Transport-Security": CheckInfo,
+		"Content-Security-Policy":   CheckFail,
+		"X-Frame-Options":           CheckFail,
+		"X-Content-Type-Options":    CheckFail,
+		"Referrer-Policy":           CheckWarn,
+		"Permissions-Policy":        CheckWarn,
+		"Cookie prefs":              CheckWarn,
+		"Cookie track":              CheckFail,
+	}
+	got := statuses(s)
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("bare: %s = %q; want %q", name, got[name], status)
+		}
+	}
+	if s.Grade != "F" || s.CSP != nil || len(s.Cookies) != 2 {
+		t.Errorf("bare: grade %s, CSP %v, %d cookies; want F, nil, 2", s.Grade, s.CSP, len(s.Cookies))
+	}
+}
+
+func TestCSPChecks(t *testing.T) {
+	tests := []struct {
+		policy string
+		warns  int
+	}{
+		{"default-src 'self'; object-src 'none'; base-uri 'self'", 0},
+		{"script-src 'self' 'unsafe-inline' 'unsafe-eval'; object-src 'none'; base-uri 'self'", 2},
+		{"script-src 'unsafe-inline' 'sha256-abc='; object-src 'none'; base-uri 'self'", 0},
+		{"script-src https: *; object-src 'none'; base-uri 'self'", 2},
+		{"img-src *", 3}, // No script-src/default-src, object-src or base-uri
+	}
+	for _, tt := range tests {
+		csp := parseCSP(http.Header{"Content-Security-Policy": {tt.policy}})
+		warns := 0
+		for _, c := range cspChecks(csp) {
+			if c.Status == CheckWarn {
+				warns++
+			}
+		}
+		if warns != tt.warns {
+			t.Errorf("%q: %d warnings; want %d (%+v)", tt.policy, warns, tt.warns, cspChecks(csp))
+		}
+	}
+
+	csp := parseCSP(http.Header{"Content-Security-Policy-Report-Only": {"default-src 'none'"}})
+	if csp == nil || !csp.ReportOnly || csp.Directives["default-src"][0] != "'none'" {
+		t.Errorf("report-only policy parsed as %+v", csp)
+	}
+}
+
+func TestAnalyzeURL_SecurityTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("Strict-Transport-Security", "max-age=600")
+		_, _ = w.Write([]byte("<title>secure</title>"))
+	}))
+	defer ts.Close()
+
+	// Trust the test server's self-signed certificate
+	old := PageFetcher.Transport
+	PageFetcher.Transport = ts.Client().Transport
+	defer func() { PageFetcher.Transport = old }()
+
+	result, err := AnalyzeURL(context.Background(), ts.URL, Options{})
+	if err != nil {
+		t.Fatalf("AnalyzeURL: %v", err)
+	}
+	s := result.Security
+	if s == nil || s.TLS == nil {
+		t.Fatalf("Security = %+v; want a TLS report", s)
+	}
+	// httptest's certificate: issued by "Acme Co", expires in 2084
+	if s.TLS.Version == "" || s.TLS.Cipher == "" || s.TLS.Issuer != "Acme Co" || s.TLS.DaysLeft < certWarnDays {
+		t.Errorf("TLS = %+v", s.TLS)
+	}
+	got := statuses(*s)
+	if got["TLS version"] != CheckPass || got["Certificate"] != CheckPass || got["Strict-Transport-Security"] != CheckWarn {
+		t.Errorf("checks = %v; want TLS and certificate pass, short HSTS warned", got)
+	}
+}
+
+func TestAuditSecurity_TLS(t *testing.T) {
+	now := time.Now()
+	certFor := func(host string, daysLeft int) []*x509.Certificate {
+		return []*x509.Certificate{{
+			Subject:  pkix.Name{CommonName: host},
+			DNSNames: []string{host},
+			Issuer:   pkix.Name{Organization: []string{"Test CA"}},
+			NotAfter: now.Add(time.Duration(daysLeft)*24*time.Hour + time.Hour),
+		}}
+	}
+	cert := func(daysLeft int) []*x509.Certificate { return certFor("example.com", daysLeft) }
+	tests := []struct {
+		name    string
+		state   tls.ConnectionState
+		certErr error
+		want    map[string]string
+	}{
+		{"modern", tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_AES_128_GCM_SHA256, PeerCertificates: cert(90)},
+			nil, map[string]string{"TLS version": CheckPass, "TLS cipher": CheckPass, "Certificate": CheckPass}},
+		{"expires within a month", tls.ConnectionState{Version: tls.VersionTLS12, CipherSuite: tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, PeerCertificates: cert(20)},
+			nil, map[string]string{"Certificate": CheckWarn}},
+		{"expires within a week", tls.ConnectionState{Version: tls.VersionTLS12, CipherSuite: tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, PeerCertificates: cert(3)},
+			nil, map[string]string{"Certificate": CheckFail}},
+		{"old protocol and cipher", tls.ConnectionState{Version: tls.VersionTLS10, CipherSuite: tls.TLS_RSA_WITH_RC4_128_SHA, PeerCertificates: cert(90)},
+			nil, map[string]string{"TLS version": CheckFail, "TLS cipher": CheckFail}},
+		{"no certificate", tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_AES_128_GCM_SHA256},
+			nil, map[string]string{"Certificate": CheckFail}},
+		{"wrong host", tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_AES_128_GCM_SHA256, PeerCertificates: certFor("other.com", 90)},
+			nil, map[string]string{"Certificate": CheckFail}},
+		{"expired", tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_AES_128_GCM_SHA256, PeerCertificates: cert(-3)},
+			x509.CertificateInvalidError{Reason: x509.Expired}, map[string]string{"Certificate": CheckFail}},
+		{"untrusted", tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_AES_128_GCM_SHA256, PeerCertificates: cert(90)},
+			x509.UnknownAuthorityError{}, map[string]string{"Certificate": CheckFail}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statuses(auditSecurity("https://example.com/", http.Header{}, &tt.state, tt.certErr, now))
+			for name, status := range tt.want {
+				if got[name] != status {
+					t.Errorf("%s = %q; want %q", name, got[name], status)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeURL_BadCertificatesAreReported(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<title>x</title>"))
+	}))
+	defer ts.Close()
+
+	// certificate runs AnalyzeURL and returns the TLS report and the Certificate check
+	certificate := func(t *testing.T, rawURL string) (*TLSReport, SecurityCheck) {
+		t.Helper()
+		result, err := AnalyzeURL(context.Background(), rawURL, Options{})
+		if err != nil {
+			t.Fatalf("AnalyzeURL: %v; want the page analyzed despite the certificate", err)
+		}
+		for _, c := range result.Security.Checks {
+			if c.Name == "Certificate" {
+				return result.Security.TLS, c
+			}
+		}
+		t.Fatalf("no Certificate check in %+v", result.Security.Checks)
+		return nil, SecurityCheck{}
+	}
+
+	t.Run("wrong host", func(t *testing.T) {
+		old := PageFetcher.Transport
+		PageFetcher.Transport = ts.Client().Transport // Trusts the test CA
+		defer func() { PageFetcher.Transport = old }()
+
+		// The test certificate covers 127.0.0.1 and example.com, not localhost
+		report, check := certificate(t, strings.Replace(ts.URL, "127.0.0.1", "localhost", 1))
+		if report.HostMatches || !report.Trusted || check.Status != CheckFail || !strings.Contains(check.Message, "localhost") {
+			t.Errorf("TLS = %+v, check = %+v; want a trusted certificate failing for localhost", report, check)
+		}
+	})
+
+	t.Run("untrusted", func(t *testing.T) {
+		// The default transport only trusts the system roots
+		report, check := certificate(t, ts.URL)
+		if !report.HostMatches || report.Trusted || check.Status != CheckFail || !strings.Contains(check.Message, "not trusted") {
+			t.Errorf("TLS = %+v, check = %+v; want an untrusted certificate failing", report, check)
+		}
+	})
+	t.Run("old protocol", func(t *testing.T) {
+		old := httptest.NewUnstartedServer(ts.Config.Handler)
+		old.TLS = &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS11}
+		old.StartTLS()
+		defer old.Close()
+
+		result, err := AnalyzeURL(context.Background(), old.URL, Options{})
+		if err != nil {
+			t.Fatalf("AnalyzeURL: %v; want TLS 1.1 accepted so it can be reported", err)
+		}
+		if got := statuses(*result.Security)["TLS version"]; got != CheckFail {
+			t.Errorf("TLS version = %q on %s; want fail", got, result.Security.TLS.Version)
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// SEO is the search/social metadata found in the page's <meta> and <link> tags
+type SEO struct {
+	Description string            `json:"description"`  // <meta name="description">
+	Robots      string            `json:"robots"`       // <meta name="robots">, e.g. "noindex, follow"
+	Canonical   string            `json:"canonical"`    // <link rel="canonical" href>
+	Viewport    string            `json:"viewport"`     // <meta name="viewport">
+	Hreflang    []HreflangLink    `json:"hreflang"`     // <link rel="alternate" hreflang>
+	OpenGraph   map[string]string `json:"open_graph"`   // og:title → "...", og:image → "..." (first value wins)
+	TwitterCard map[string]string `json:"twitter_card"` // twitter:card → "summary", ...
+	Issues      []SEOIssue        `json:"issues"`       // Missing / duplicated / too long fields
+}
+
+// HreflangLink is one language alternate of the page
+type HreflangLink struct {
+	Lang string `json:"lang"` // e.g. "en-gb", "x-default"
+	Href string `json:"href"`
+}
+
+// Values of SEOIssue.Problem
+const (
+	SEOMissing   = "missing"
+	SEODuplicate = "duplicate"
+	SEOTooLong   = "too_long"
+)
+
+// SEOIssue flags one problem with one field
+type SEOIssue struct {
+	Field   string `json:"field"`   // "description", "canonical", "og:title", "hreflang", ...
+	Problem string `json:"problem"` // missing / duplicate / too_long
+	Message string `json:"message"` // Human-readable, for the results page
+}
+
+// IssuesFor returns the issues of one field (used by the results template)
+func (s SEO) IssuesFor(field string) []SEOIssue {
+	var out []SEOIssue
+	for _, issue := range s.Issues {
+		if issue.Field == field {
+			out = append(out, issue)
+		}
+	}
+	return out
+}
+
+// MissingWithPrefix returns the "missing" issues of fields starting with prefix,
+// e.g. "og:" → og:type, og:image... (used by the results template, whose
+// Open Graph / Twitter tables only have rows for tags that exist)
+func (s SEO) MissingWithPrefix(prefix string) []SEOIssue {
+	var out []SEOIssue
+	for _, issue := range s.Issues {
+		if issue.Problem == SEOMissing && strings.HasPrefix(issue.Field, prefix) {
+			out = append(out, issue)
+		}
+	}
+	return out
+}
+
+// seoRequired are the fields every indexable page should have:
+// the basics plus the four properties the Open Graph protocol requires
+var seoRequired = []string{
+	"description", "canonical", "viewport",
+	"og:title", "og:type", "og:image", "og:url",
+	"twitter:card",
+}
+
+// seoMaxLength is roughly where search results and social cards cut text off
+var seoMaxLength = map[string]int{
+	"description":         160,
+	"og:title":            95,
+	"og:description":      200,
+	"twitter:title":       70,
+	"twitter:description": 200,
+}
+
+// seoRepeatable are properties that may legally appear more than once
+// (Open Graph "arrays": several images, videos, alternate locales...)
+var seoRepeatable = []string{"og:image", "og:video", "og:audio", "og:locale:alternate"}
+
+// seoCollector gathers every value during the DOM traversal;
+// build() then turns them into an SEO section with issues
+type seoCollector struct {
+	values   map[string][]string // field → every value seen, in document order
+	hreflang []HreflangLink
+}
+
+func newSEOCollector() *seoCollector {
+	return &seoCollector{values: make(map[string][]string)}
+}
+
+// meta handles <meta name="..." content="..."> and <meta property="og:..." content="...">
+func (c *seoCollector) meta(n *html.Node) {
+	key := strings.ToLower(attrValue(n, "name"))
+	if key == "" {
+		key = strings.ToLower(attrValue(n, "property")) // Open Graph uses property=
+	}
+	switch {
+	case key == "description", key == "robots", key == "viewport",
+		strings.HasPrefix(key, "og:"), strings.HasPrefix(key, "twitter:"):
+		c.values[key] = append(c.values[key], strings.TrimSpace(attrValue(n, "content")))
+	}
+}
+
+// link handles <link rel="canonical"> and <link rel="alternate" hreflang="...">
+func (c *seoCollector) link(n *html.Node) {
+	rels := strings.Fields(strings.ToLower(attrValue(n, "rel")))
+	href := strings.TrimSpace(attrValue(n, "href"))
+	for _, rel := range rels {
+		switch rel {
+		case "canonical":
+			c.values["canonical"] = append(c.values["canonical"], href)
+		case "alternate":
+			if lang := strings.ToLower(strings.TrimSpace(attrValue(n, "hreflang"))); lang != "" {
+				c.hreflang = append(c.hreflang, HreflangLink{Lang: lang, Href: href})
+			}
+		}
+	}
+}
+
+// build picks the first value of each field and flags what's wrong
+func (c *seoCollector) build() SEO {
+	first := func(key string) string {
+		if v := c.values[key]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+
+	seo := SEO{
+		Description: first("description"),
+		Robots:      first("robots"),
+		Canonical:   first("canonical"),
+		Viewport:    first("viewport"),
+		Hreflang:    c.hreflang,
+		OpenGraph:   make(map[string]string),
+		TwitterCard: make(map[string]string),
+		Issues:      []SEOIssue{},
+	}
+	if seo.Hreflang == nil {
+		seo.Hreflang = []HreflangLink{}
+	}
+	for key := range c.values {
+		switch {
+		case strings.HasPrefix(key, "og:"):
+			seo.OpenGraph[key] = first(key)
+		case strings.HasPrefix(key, "twitter:"):
+			seo.TwitterCard[key] = first(key)
+		}
+	}
+
+	// === Missing ===
+	for _, key := range seoRequired {
+		if first(key) == "" {
+			seo.Issues = append(seo.Issues, SEOIssue{Field: key, Problem: SEOMissing, Message: key + " is missing"})
+		}
+	}
+
+	// === Duplicated / too long (sorted keys → stable output) ===
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values := c.values[key]
+		if len(values) > 1 && !isRepeatable(key) {
+			seo.Issues = append(seo.Issues, SEOIssue{Field: key, Problem: SEODuplicate,
+				Message: fmt.Sprintf("%s appears %d times", key, len(values))})
+		}
+		if limit, ok := seoMaxLength[key]; ok {
+			if n := utf8.RuneCountInString(values[0]); n > limit {
+				seo.Issues = append(seo.Issues, SEOIssue{Field: key, Problem: SEOTooLong,
+					Message: fmt.Sprintf("%s is %d characters (recommended max %d)", key, n, limit)})
+			}
+		}
+	}
+
+	// === The same language listed twice ===
+	seenLang := make(map[string]bool)
+	for _, alt := range c.hreflang {
+		if seenLang[alt.Lang] {
+			seo.Issues = append(seo.Issues, SEOIssue{Field: "hreflang", Problem: SEODuplicate,
+				Message: fmt.Sprintf("hreflang %q is listed more than once", alt.Lang)})
+		}
+		seenLang[alt.Lang] = true
+	}
+
+	return seo
+}
+
+// isRepeatable reports whether key (or its parent, e.g. og:image:width → og:image) may repeat
+func isRepeatable(key string) bool {
+	for _, r := range seoRepeatable {
+		if key == r || strings.HasPrefix(key, r+":") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzePage_SEO(t *testing.T) {
+	longDesc := strings.Repeat("a", 161)
+	page := `<!DOCTYPE html><html><head>
+<title>T</title>
+<meta name="description" content="` + longDesc + `">
+<meta name="Description" content="second one">
+<meta name="robots" content="noindex, follow">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<link rel="canonical" href="https://site.com/page">
+<link rel="alternate" hreflang="en" href="https://site.com/en/page">
+<link rel="alternate" hreflang="de" href="https://site.com/de/page">
+<link rel="alternate" hreflang="EN" href="https://site.com/en-2/page">
+<meta property="og:title" content="OG title">
+<meta property="og:type" content="article">
+<meta property="og:image" content="https://site.com/1.png">
+<meta property="og:image" content="https://site.com/2.png">
+<meta name="twitter:card" content="summary">
+<meta name="twitter:card" content="summary_large_image">
+</head><body></body></html>`
+
+	result, err := AnalyzePage(strings.NewReader(page), "")
+	if err != nil {
+		t.Fatalf("AnalyzePage: %v", err)
+	}
+	seo := result.SEO
+
+	if seo.Description != longDesc || seo.Robots != "noindex, follow" ||
+		seo.Canonical != "https://site.com/page" || seo.Viewport != "width=device-width, initial-scale=1" {
+		t.Errorf("fields = %+v", seo)
+	}
+	wantHreflang := []HreflangLink{
+		{Lang: "en", Href: "https://site.com/en/page"},
+		{Lang: "de", Href: "https://site.com/de/page"},
+		{Lang: "en", Href: "https://site.com/en-2/page"},
+	}
+	if !reflect.DeepEqual(seo.Hreflang, wantHreflang) {
+		t.Errorf("Hreflang = %+v; want %+v", seo.Hreflang, wantHreflang)
+	}
+	wantOG := map[string]string{"og:title": "OG title", "og:type": "article", "og:image": "https://site.com/1.png"}
+	if !reflect.DeepEqual(seo.OpenGraph, wantOG) {
+		t.Errorf("OpenGraph = %v; want %v", seo.OpenGraph, wantOG)
+	}
+	if seo.TwitterCard["twitter:card"] != "summary" {
+		t.Errorf("TwitterCard = %v; want first value", seo.TwitterCard)
+	}
+
+	// og:image may repeat; description, twitter:card and hreflang "en" may not
+	type flag struct{ field, problem string }
+	var got []flag
+	for _, issue := range seo.Issues {
+		got = append(got, flag{issue.Field, issue.Problem})
+	}
+	want := []flag{
+		{"og:url", SEOMissing},
+		{"description", SEODuplicate},
+		{"description", SEOTooLong},
+		{"twitter:card", SEODuplicate},
+		{"hreflang", SEODuplicate},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Issues = %v; want %v", got, want)
+	}
+}
+
+func TestAnalyzePage_SEO_Empty(t *testing.T) {
+	result, err := AnalyzePage(strings.NewReader(`<html><head><title>x</title></head></html>`), "")
+	if err != nil {
+		t.Fatalf("AnalyzePage: %v", err)
+	}
+
+	// Every required field is flagged, nothing else
+	var missing []string
+	for _, issue := range result.SEO.Issues {
+		if issue.Problem != SEOMissing {
+			t.Errorf("unexpected issue %+v", issue)
+		}
+		missing = append(missing, issue.Field)
+	}
+	if !reflect.DeepEqual(missing, seoRequired) {
+		t.Errorf("missing = %v; want %v", missing, seoRequired)
+	}
+	if got := result.SEO.MissingWithPrefix("og:"); len(got) != 4 {
+		t.Errorf("MissingWithPrefix(og:) = %v; want the 4 required Open Graph properties", got)
+	}
+}
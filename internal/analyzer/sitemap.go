@@ -0,0 +1,326 @@
+package analyzer
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sitemap limits (set from SITEMAP_MAX_URLS and SITEMAP_CONCURRENCY in main.go)
+var (
+	SitemapMaxURLs     = 500 // Page URLs analyzed per request; the rest are only counted
+	SitemapMaxFiles    = 50  // Sitemap files read (an index can point to many more)
+	SitemapConcurrency = 4   // Pages analyzed at once per request; every request also takes a shared pool token
+)
+
+// maxSitemapBytes is the protocol's size limit for one (uncompressed) sitemap
+const maxSitemapBytes = 50 << 20
+
+// SitemapFile is one sitemap (or sitemap index) we tried to read
+type SitemapFile struct {
+	URL      string `json:"url"`
+	URLs     int    `json:"urls"`     // <url> entries
+	Sitemaps int    `json:"sitemaps"` // <sitemap> entries (sitemap index)
+	Error    string `json:"error,omitempty"`
+}
+
+// SitemapProblem is a listed URL that could not be analyzed
+type SitemapProblem struct {
+	URL   string         `json:"url"`
+	Error *AnalysisError `json:"error"`
+}
+
+// SitemapRedirect is a listed URL that redirects somewhere else –
+// the sitemap should list FinalURL instead
+type SitemapRedirect struct {
+	URL      string `json:"url"`
+	FinalURL string `json:"final_url"`
+}
+
+// MissingURL is an internal link that the sitemap doesn't list
+type MissingURL struct {
+	URL     string   `json:"url"`
+	FoundOn []string `json:"found_on"`
+}
+
+// SitemapSummary adds up the whole report
+type SitemapSummary struct {
+	SitemapFiles       int  `json:"sitemap_files"`        // Files read successfully
+	URLs               int  `json:"urls"`                 // Distinct page URLs listed
+	Analyzed           int  `json:"analyzed"`             // Pages analyzed (at most SitemapMaxURLs)
+	Failed             int  `json:"failed"`               // Listed URLs that returned an error
+	Redirected         int  `json:"redirected"`           // Listed URLs that redirect
+	MissingFromSitemap int  `json:"missing_from_sitemap"` // Internal links not listed
+	Truncated          bool `json:"truncated"`            // Hit SitemapMaxURLs or SitemapMaxFiles
+}
+
+// SitemapReport is the response of POST /api/v1/sitemap
+type SitemapReport struct {
+	Site               string            `json:"site"`
+	Sitemaps           []SitemapFile     `json:"sitemaps"`
+	Summary            SitemapSummary    `json:"summary"`
+	Pages              []BatchItem       `json:"pages"`                // Sitemap order
+	Errors             []SitemapProblem  `json:"errors"`               // Listed URLs that failed
+	Redirects          []SitemapRedirect `json:"redirects"`            // Listed URLs that redirect
+	MissingFromSitemap []MissingURL      `json:"missing_from_sitemap"` // Sorted by URL
+}
+
+// apiSitemapRequest is the JSON body of POST /api/v1/sitemap
+type apiSitemapRequest struct {
+	URL        string `json:"url"`         // Any URL on the site
+	SitemapURL string `json:"sitemap_url"` // Optional: skip discovery and read this one
+	Refresh    bool   `json:"refresh"`
+}
+
+// sitemapXML covers both file types: <urlset><url><loc> and <sitemapindex><sitemap><loc>
+type sitemapXML struct {
+	XMLName  xml.Name
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// AnalyzeSitemap finds the site's sitemap(s), analyzes every listed page
+// (SitemapConcurrency at a time) and reports listed URLs that fail or
+// redirect, plus internal links that the sitemap forgot.
+// sitemapURL skips discovery; leave it empty to use robots.txt / /sitemap.xml.
+func AnalyzeSitemap(ctx context.Context, site, sitemapURL string, refresh bool) (*SitemapReport, error) {
+	if err := ValidateURL(site); err != nil {
+		return nil, err
+	}
+
+	// === STEP 1: Find and read the sitemap files ===
+	sources := []string{sitemapURL}
+	if sitemapURL == "" {
+		sources = discoverSitemaps(ctx, site)
+	}
+	report := &SitemapReport{Site: site}
+	listed, truncated := readSitemaps(ctx, sources, report)
+	if report.Summary.SitemapFiles == 0 {
+		return nil, newAnalysisError(http.StatusBadGateway, ErrCodeSitemapNotFound,
+			"No readable sitemap found (tried %s)", strings.Join(sources, ", "))
+	}
+	report.Summary.URLs = len(listed)
+
+	// === STEP 2: Analyze the listed pages ===
+	toAnalyze := listed
+	if len(toAnalyze) > SitemapMaxURLs {
+		toAnalyze = toAnalyze[:SitemapMaxURLs]
+		truncated = true
+	}
+	report.Pages = analyzeURLs(ctx, toAnalyze, refresh, NewWorkerPool(SitemapConcurrency))
+	report.Summary.Analyzed = len(report.Pages)
+	report.Summary.Truncated = truncated
+
+	// === STEP 3: Errors, redirects and missing URLs ===
+	report.Errors = []SitemapProblem{}
+	report.Redirects = []SitemapRedirect{}
+	for _, p := range report.Pages {
+		switch {
+		case p.Error != nil:
+			report.Errors = append(report.Errors, SitemapProblem{URL: p.URL, Error: p.Error})
+		case p.Result.FinalURL != "":
+			report.Redirects = append(report.Redirects, SitemapRedirect{URL: p.URL, FinalURL: p.Result.FinalURL})
+		}
+	}
+	report.MissingFromSitemap = missingFromSitemap(listed, report.Pages)
+
+	report.Summary.Failed = len(report.Errors)
+	report.Summary.Redirected = len(report.Redirects)
+	report.Summary.MissingFromSitemap = len(report.MissingFromSitemap)
+	return report, nil
+}
+
+// discoverSitemaps lists the sitemaps robots.txt points to,
+// or the conventional /sitemap.xml when it names none
+func discoverSitemaps(ctx context.Context, site string) []string {
+	u, err := url.Parse(site)
+	if err != nil {
+		return nil
+	}
+	if sitemaps := robotsFor(ctx, u).sitemaps; len(sitemaps) > 0 {
+		return sitemaps
+	}
+	return []string{u.Scheme + "://" + u.Host + "/sitemap.xml"}
+}
+
+// readSitemaps reads the sources breadth-first, following sitemap indexes,
+// and returns the distinct page URLs in the order they were listed.
+// Every file tried is recorded in report.Sitemaps.
+func readSitemaps(ctx context.Context, sources []string, report *SitemapReport) (pages []string, truncated bool) {
+	queue := append([]string(nil), sources...)
+	seenFile := make(map[string]bool)
+	seenPage := make(map[string]bool)
+
+	for len(queue) > 0 && ctx.Err() == nil {
+		if len(report.Sitemaps) >= SitemapMaxFiles {
+			return pages, true
+		}
+		file := queue[0]
+		queue = queue[1:]
+		if seenFile[file] {
+			continue
+		}
+		seenFile[file] = true
+
+		doc, err := fetchSitemap(ctx, file)
+		if err != nil {
+			report.Sitemaps = append(report.Sitemaps, SitemapFile{URL: file, Error: err.Error()})
+			continue
+		}
+		report.Sitemaps = append(report.Sitemaps, SitemapFile{URL: file, URLs: len(doc.URLs), Sitemaps: len(doc.Sitemaps)})
+		report.Summary.SitemapFiles++
+
+		for _, s := range doc.Sitemaps {
+			if loc := strings.TrimSpace(s.Loc); loc != "" {
+				queue = append(queue, loc)
+			}
+		}
+		for _, p := range doc.URLs {
+			loc := strings.TrimSpace(p.Loc)
+			if loc == "" || seenPage[crawlKey(loc)] {
+				continue
+			}
+			seenPage[crawlKey(loc)] = true
+			pages = append(pages, loc)
+		}
+	}
+	return pages, false
+}
+
+// fetchSitemap downloads one sitemap file and parses it.
+// Gzipped files (sitemap.xml.gz) are recognised by their magic bytes,
+// so it doesn't matter what Content-Type the server sends.
+func fetchSitemap(ctx context.Context, rawURL string) (*sitemapXML, error) {
+	if err := ValidateURL(rawURL); err != nil {
+		return nil, err
+	}
+	resp, err := fetchPage(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	br := bufio.NewReader(io.LimitReader(resp.Body, maxSitemapBytes))
+	var body io.Reader = br
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("bad gzip: %v", err)
+		}
+		defer gz.Close()
+		body = io.LimitReader(gz, maxSitemapBytes)
+	}
+	return parseSitemap(body)
+}
+
+// parseSitemap decodes a <urlset> or <sitemapindex> document
+func parseSitemap(r io.Reader) (*sitemapXML, error) {
+	var doc sitemapXML
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid sitemap XML: %v", err)
+	}
+	if doc.XMLName.Local != "urlset" && doc.XMLName.Local != "sitemapindex" {
+		return nil, fmt.Errorf("not a sitemap: root element is <%s>", doc.XMLName.Local)
+	}
+	return &doc, nil
+}
+
+// missingFromSitemap collects internal links on the analyzed pages that the
+// sitemap doesn't list. Broken links are left out (they aren't pages to list),
+// and so are links that redirect to a listed URL.
+func missingFromSitemap(listed []string, pages []BatchItem) []MissingURL {
+	inSitemap := make(map[string]bool, len(listed))
+	for _, u := range listed {
+		inSitemap[crawlKey(u)] = true
+	}
+
+	foundOn := make(map[string]map[string]bool)
+	for _, p := range pages {
+		if p.Result == nil {
+			continue
+		}
+		for _, link := range p.Result.Links.Items {
+			if link.Kind != LinkInternal || link.Health == LinkBroken {
+				continue
+			}
+			key := crawlKey(link.URL)
+			if inSitemap[key] || (link.FinalURL != "" && inSitemap[crawlKey(link.FinalURL)]) {
+				continue
+			}
+			if foundOn[key] == nil {
+				foundOn[key] = make(map[string]bool)
+			}
+			foundOn[key][p.URL] = true
+		}
+	}
+
+	out := make([]MissingURL, 0, len(foundOn))
+	for u, pages := range foundOn {
+		out = append(out, MissingURL{URL: u, FoundOn: sortedKeys(pages)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].URL < out[j].URL })
+	return out
+}
+
+// SitemapHandler serves POST /api/v1/sitemap:
+// {"url": "https://example.com"} or {"url": "...", "sitemap_url": "https://example.com/sitemap_index.xml"}
+func SitemapHandler(log *logrus.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// === STEP 1: Only allow POST ===
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeAPIError(w, newAnalysisError(http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "Method not allowed"))
+			return
+		}
+
+		// === STEP 2: Decode the request ===
+		var req apiSitemapRequest
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		if req.SitemapURL != "" {
+			if err := ValidateURL(req.SitemapURL); err != nil {
+				writeAPIError(w, err)
+				return
+			}
+		}
+
+		log.WithFields(logrus.Fields{
+			"url":         req.URL,
+			"sitemap_url": req.SitemapURL,
+		}).Info("Starting sitemap analysis")
+
+		// === STEP 3: Read the sitemap, analyze the pages, report ===
+		report, err := AnalyzeSitemap(r.Context(), req.URL, req.SitemapURL, req.Refresh)
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+
+		log.WithFields(logrus.Fields{
+			"url":     req.URL,
+			"urls":    report.Summary.URLs,
+			"failed":  report.Summary.Failed,
+			"missing": report.Summary.MissingFromSitemap,
+		}).Info("Sitemap analysis finished")
+
+		writeJSON(w, http.StatusOK, report)
+	}
+}
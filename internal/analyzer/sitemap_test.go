@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSitemapHandler(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fill := func(s string) []byte { return []byte(strings.ReplaceAll(s, "{{base}}", ts.URL)) }
+		switch r.URL.Path {
+		case "/robots.txt":
+			_, _ = w.Write(fill("User-agent: *\nDisallow:\nSitemap: {{base}}/sitemap_index.xml\n"))
+		case "/sitemap_index.xml":
+			_, _ = w.Write(fill(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+				<sitemap><loc>{{base}}/sitemap-main.xml</loc></sitemap>
+				<sitemap><loc>{{base}}/sitemap-more.xml.gz</loc></sitemap></sitemapindex>`))
+		case "/sitemap-main.xml":
+			_, _ = w.Write(fill(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+				<url><loc>{{base}}/</loc></url><url><loc>{{base}}/a</loc></url><url><loc>{{base}}/a#dup</loc></url></urlset>`))
+		case "/sitemap-more.xml.gz":
+			// Gzipped, served with a generic content type
+			w.Header().Set("Content-Type", "application/octet-stream")
+			var out bytes.Buffer
+			zw := gzip.NewWriter(&out)
+			_, _ = zw.Write(fill(`<?xml version="1.0"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+				<url><loc>{{base}}/old</loc></url><url><loc>{{base}}/gone</loc></url></urlset>`))
+			zw.Close()
+			_, _ = w.Write(out.Bytes())
+		case "/":
+			_, _ = w.Write([]byte(`<a href="/a">a</a><a href="/unlisted">new</a><a href="/nowhere">broken</a>`))
+		case "/a":
+			_, _ = w.Write([]byte(`<a href="/unlisted">new</a><a href="/">home</a>`))
+		case "/unlisted":
+			_, _ = w.Write([]byte(`<title>Not in the sitemap</title>`))
+		case "/old":
+			http.Redirect(w, r, "/a", http.StatusMovedPermanently)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	h := SitemapHandler(logrus.New())
+	post := func(body string) (*httptest.ResponseRecorder, SitemapReport) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sitemap", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		var report SitemapReport
+		_ = json.Unmarshal(rr.Body.Bytes(), &report)
+		return rr, report
+	}
+
+	t.Run("discovered via robots.txt", func(t *testing.T) {
+		rr, report := post(`{"url":"` + ts.URL + `","refresh":true}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d; want 200; body: %s", rr.Code, rr.Body.String())
+		}
+
+		// index + 2 children; "/a#dup" is the same page as "/a"
+		want := SitemapSummary{SitemapFiles: 3, URLs: 4, Analyzed: 4, Failed: 1, Redirected: 1, MissingFromSitemap: 1}
+		if report.Summary != want {
+			t.Errorf("Summary = %+v; want %+v", report.Summary, want)
+		}
+		if len(report.Errors) != 1 || report.Errors[0].URL != ts.URL+"/gone" || report.Errors[0].Error.Code != ErrCodeUpstreamStatus {
+			t.Errorf("Errors = %+v; want /gone with %s", report.Errors, ErrCodeUpstreamStatus)
+		}
+		wantRedirects := []SitemapRedirect{{URL: ts.URL + "/old", FinalURL: ts.URL + "/a"}}
+		if !reflect.DeepEqual(report.Redirects, wantRedirects) {
+			t.Errorf("Redirects = %+v; want %+v", report.Redirects, wantRedirects)
+		}
+		// "/unlisted" is linked from "/" and "/a" (and from "/old", which lands on "/a");
+		// the broken "/nowhere" isn't a page, so it's not reported as missing
+		wantMissing := []MissingURL{{URL: ts.URL + "/unlisted", FoundOn: []string{ts.URL + "/", ts.URL + "/a", ts.URL + "/old"}}}
+		if !reflect.DeepEqual(report.MissingFromSitemap, wantMissing) {
+			t.Errorf("MissingFromSitemap = %+v; want %+v", report.MissingFromSitemap, wantMissing)
+		}
+	})
+
+	t.Run("explicit sitemap_url and URL limit", func(t *testing.T) {
+		oldMax := SitemapMaxURLs
+		defer func() { SitemapMaxURLs = oldMax }()
+		SitemapMaxURLs = 1
+
+		_, report := post(`{"url":"` + ts.URL + `","sitemap_url":"` + ts.URL + `/sitemap-main.xml"}`)
+		if report.Summary.SitemapFiles != 1 || report.Summary.URLs != 2 || report.Summary.Analyzed != 1 || !report.Summary.Truncated {
+			t.Errorf("Summary = %+v; want 1 file, 2 URLs, 1 analyzed, truncated", report.Summary)
+		}
+	})
+
+	t.Run("no sitemap", func(t *testing.T) {
+		rr, _ := post(`{"url":"` + ts.URL + `","sitemap_url":"` + ts.URL + `/missing.xml"}`)
+		if rr.Code != http.StatusBadGateway || !strings.Contains(rr.Body.String(), ErrCodeSitemapNotFound) {
+			t.Errorf("status = %d, body = %s; want 502 %s", rr.Code, rr.Body.String(), ErrCodeSitemapNotFound)
+		}
+	})
+}
+
+func TestParseSitemap_RejectsOtherXML(t *testing.T) {
+	if _, err := parseSitemap(strings.NewReader(`<rss><channel/></rss>`)); err == nil {
+		t.Error("expected an error for a non-sitemap document")
+	}
+}
@@ -0,0 +1,151 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DefaultDeniedCIDRs are the networks we refuse to connect to unless
+// explicitly allowed. Without this, anyone could make the server fetch
+// http://127.0.0.1/admin or the cloud metadata service (SSRF).
+var DefaultDeniedCIDRs = []string{
+	"0.0.0.0/8",      // "this" network
+	"10.0.0.0/8",     // RFC1918 private
+	"100.64.0.0/10",  // carrier-grade NAT (also Alibaba metadata 100.100.100.200)
+	"127.0.0.0/8",    // loopback
+	"169.254.0.0/16", // link-local (AWS/GCP/Azure metadata 169.254.169.254)
+	"172.16.0.0/12",  // RFC1918 private
+	"192.0.0.0/24",   // IETF protocol assignments
+	"192.168.0.0/16", // RFC1918 private
+	"198.18.0.0/15",  // benchmarking
+	"224.0.0.0/4",    // multicast
+	"240.0.0.0/4",    // reserved + broadcast
+	"::/128",         // unspecified
+	"::1/128",        // loopback
+	"64:ff9b::/96",   // NAT64 (can reach IPv4 internals)
+	"fc00::/7",       // unique local (incl. AWS metadata fd00:ec2::254)
+	"fe80::/10",      // link-local
+	"ff00::/8",       // multicast
+}
+
+// AddressPolicy decides which IP addresses outgoing requests may reach.
+// Allow wins over Deny, so a single internal host can be whitelisted
+// without opening up the whole private range.
+type AddressPolicy struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// NewAddressPolicy builds a policy from CIDR strings, e.g. "10.0.0.0/8".
+// A bare IP ("10.1.2.3") is treated as a single-address prefix.
+func NewAddressPolicy(allow, deny []string) (*AddressPolicy, error) {
+	p := &AddressPolicy{}
+	var err error
+	if p.allow, err = parsePrefixes(allow); err != nil {
+		return nil, err
+	}
+	if p.deny, err = parsePrefixes(deny); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// parsePrefixes turns CIDR strings into prefixes (empty entries are skipped)
+func parsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	var out []netip.Prefix
+	for _, c := range cidrs {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		if !strings.Contains(c, "/") {
+			addr, err := netip.ParseAddr(c)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address %q: %w", c, err)
+			}
+			out = append(out, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(c)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", c, err)
+		}
+		out = append(out, prefix.Masked())
+	}
+	return out, nil
+}
+
+// Check returns a *BlockedAddressError if ip may not be contacted
+func (p *AddressPolicy) Check(ip netip.Addr) error {
+	ip = ip.Unmap() // ::ffff:127.0.0.1 → 127.0.0.1, so v4 rules apply
+	for _, prefix := range p.allow {
+		if prefix.Contains(ip) {
+			return nil
+		}
+	}
+	for _, prefix := range p.deny {
+		if prefix.Contains(ip) {
+			return &BlockedAddressError{IP: ip}
+		}
+	}
+	return nil
+}
+
+// BlockedAddressError is returned when a target resolves to a refused IP
+type BlockedAddressError struct {
+	IP netip.Addr
+}
+
+func (e *BlockedAddressError) Error() string {
+	return fmt.Sprintf("address %s is not allowed (private, loopback, link-local or otherwise blocked)", e.IP)
+}
+
+// TargetPolicy is the policy enforced by guardedTransport.
+// main.go can replace it (SSRF_ALLOW_CIDRS / SSRF_DENY_CIDRS).
+var TargetPolicy = mustAddressPolicy(nil, DefaultDeniedCIDRs)
+
+// mustAddressPolicy is NewAddressPolicy for hard-coded lists (panics on typos)
+func mustAddressPolicy(allow, deny []string) *AddressPolicy {
+	p, err := NewAddressPolicy(allow, deny)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// guardedDialer checks the *resolved* IP right before connecting.
+// Checking at dial time (not when parsing the URL) also covers:
+// - hostnames that resolve to private IPs ("localtest.me")
+// - DNS rebinding (name resolves differently on the second lookup)
+// - redirects to internal addresses (every hop dials again)
+var guardedDialer = &net.Dialer{
+	Timeout:   30 * time.Second,
+	KeepAlive: 30 * time.Second,
+	Control: func(network, address string, _ syscall.RawConn) error {
+		addrPort, err := netip.ParseAddrPort(address)
+		if err != nil {
+			return fmt.Errorf("unexpected dial address %q: %w", address, err)
+		}
+		return TargetPolicy.Check(addrPort.Addr())
+	},
+}
+
+// guardedTransport is shared by the page fetch and the link checker.
+// Proxy is disabled on purpose: with a proxy we'd dial the proxy,
+// not the target, and the IP check above would be meaningless.
+var guardedTransport = newGuardedTransport()
+
+func newGuardedTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return guardedDialer.DialContext(ctx, network, addr)
+	}
+	return t
+}
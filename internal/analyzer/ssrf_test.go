@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"testing"
+)
+
+// TestMain relaxes the SSRF guard for this package's tests:
+// httptest servers listen on 127.0.0.1, which the default policy refuses.
+// Tests that exercise the guard itself install their own policy.
+// robots.txt checks are off too (link tests use fake hosts that must not
+// be looked up for real); robots_test.go turns them back on.
+func TestMain(m *testing.M) {
+	TargetPolicy = mustAddressPolicy([]string{"127.0.0.0/8", "::1/128"}, DefaultDeniedCIDRs)
+	RespectRobots = false
+	os.Exit(m.Run())
+}
+
+// withPolicy swaps TargetPolicy for the duration of one test
+func withPolicy(t *testing.T, p *AddressPolicy) {
+	t.Helper()
+	old := TargetPolicy
+	TargetPolicy = p
+	t.Cleanup(func() { TargetPolicy = old })
+}
+
+func TestAddressPolicy_Check(t *testing.T) {
+	defaults := mustAddressPolicy(nil, DefaultDeniedCIDRs)
+
+	cases := []struct {
+		ip      string
+		blocked bool
+	}{
+		{"127.0.0.1", true},        // loopback
+		{"10.1.2.3", true},         // RFC1918
+		{"172.16.0.1", true},       // RFC1918
+		{"192.168.1.1", true},      // RFC1918
+		{"169.254.169.254", true},  // cloud metadata
+		{"::1", true},              // IPv6 loopback
+		{"fd00:ec2::254", true},    // AWS IPv6 metadata
+		{"::ffff:127.0.0.1", true}, // IPv4-mapped loopback
+		{"93.184.216.34", false},   // public
+		{"2606:4700::1111", false}, // public IPv6
+	}
+	for _, tc := range cases {
+		err := defaults.Check(netip.MustParseAddr(tc.ip))
+		if got := err != nil; got != tc.blocked {
+			t.Errorf("Check(%s) blocked = %v; want %v (err: %v)", tc.ip, got, tc.blocked, err)
+		}
+	}
+
+	// Allow list wins over deny list
+	custom, err := NewAddressPolicy([]string{"10.0.0.5"}, DefaultDeniedCIDRs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := custom.Check(netip.MustParseAddr("10.0.0.5")); err != nil {
+		t.Errorf("allowed IP was blocked: %v", err)
+	}
+	if err := custom.Check(netip.MustParseAddr("10.0.0.6")); err == nil {
+		t.Errorf("neighbouring IP should still be blocked")
+	}
+
+	if _, err := NewAddressPolicy([]string{"not-a-cidr"}, nil); err == nil {
+		t.Errorf("expected error for invalid CIDR")
+	}
+}
+
+func TestAnalyzeURL_RefusesPrivateTargets(t *testing.T) {
+	withPolicy(t, mustAddressPolicy(nil, DefaultDeniedCIDRs))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("guarded request reached the server: %s", r.URL)
+	}))
+	defer ts.Close()
+
+	_, err := AnalyzeURL(context.Background(), ts.URL, Options{})
+
+	var aerr *AnalysisError
+	if !errors.As(err, &aerr) {
+		t.Fatalf("err = %v; want *AnalysisError", err)
+	}
+	if aerr.Status != http.StatusForbidden || aerr.Code != ErrCodeBlockedTarget {
+		t.Errorf("got %d/%s; want 403/%s", aerr.Status, aerr.Code, ErrCodeBlockedTarget)
+	}
+	if aerr.Details["ip"] != "127.0.0.1" {
+		t.Errorf("details = %v; want ip 127.0.0.1", aerr.Details)
+	}
+}
+
+func TestCheckLink_BlockedTargetIsUnchecked(t *testing.T) {
+	withPolicy(t, mustAddressPolicy(nil, DefaultDeniedCIDRs))
+
+	r := LinkReport{URL: "http://127.0.0.1:1/admin"}
+	checkLink(context.Background(), &r)
+
+	if r.Health != LinkUnchecked {
+		t.Errorf("Health = %q; want %q (err: %s)", r.Health, LinkUnchecked, r.Error)
+	}
+}
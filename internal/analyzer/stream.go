@@ -0,0 +1,175 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Stream event names, in the order a client sees them:
+// parsed → link_checked (× number of links) → done
+// or, at any point, a single error event.
+const (
+	EventParsed      = "parsed"
+	EventLinkChecked = "link_checked"
+	EventDone        = "done"
+	EventError       = "error"
+)
+
+// parsedEvent is sent as soon as the DOM is parsed (links not checked yet)
+type parsedEvent struct {
+	URL          string         `json:"url"`
+	HTMLVersion  string         `json:"html_version"`
+	Doctype      Doctype        `json:"doctype"`
+	Title        string         `json:"title"`
+	Headings     map[string]int `json:"headings"`
+	HasLoginForm bool           `json:"has_login_form"`
+	Forms        []FormReport   `json:"forms"`
+	LinkCount    int            `json:"link_count"`
+}
+
+// linkCheckedEvent is sent once per link, in completion order
+type linkCheckedEvent struct {
+	Link  LinkReport `json:"link"`
+	Done  int        `json:"done"`
+	Total int        `json:"total"`
+}
+
+// doneEvent carries the complete result (same shape as the JSON API)
+type doneEvent = apiAnalyzeResponse
+
+// eventWriter sends typed events to the client and flushes each one,
+// so the browser/CLI sees it immediately instead of when the buffer fills.
+type eventWriter struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+	jsonl   bool // true = JSON lines, false = Server-Sent Events
+}
+
+// send writes one event. Errors are ignored: if the client went away,
+// the request context is cancelled and the pipeline stops on its own.
+func (e *eventWriter) send(event string, data any) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.jsonl {
+		// {"event":"link_checked","data":{...}}\n
+		line, _ := json.Marshal(struct {
+			Event string `json:"event"`
+			Data  any    `json:"data"`
+		}{event, data})
+		_, _ = fmt.Fprintf(e.w, "%s\n", line)
+	} else {
+		// event: link_checked\ndata: {...}\n\n
+		payload, _ := json.Marshal(data)
+		_, _ = fmt.Fprintf(e.w, "event: %s\ndata: %s\n\n", event, payload)
+	}
+	e.flusher.Flush()
+}
+
+// wantsJSONLines picks the stream format:
+// ?format=jsonl or Accept: application/x-ndjson → JSON lines, otherwise SSE
+func wantsJSONLines(r *http.Request) bool {
+	if f := r.URL.Query().Get("format"); f != "" {
+		return f == "jsonl" || f == "ndjson"
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/x-ndjson")
+}
+
+// StreamAnalyzeHandler serves GET /api/v1/analyze/stream?url=...[&refresh=1][&format=jsonl]
+// It runs the normal pipeline and streams its progress:
+// - SSE (text/event-stream) for browsers, via EventSource
+// - JSON lines (application/x-ndjson) for CLI tools: one {"event","data"} object per line
+func StreamAnalyzeHandler(log *logrus.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// === STEP 1: GET only (EventSource can't POST) ===
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeAPIError(w, newAnalysisError(http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "Method not allowed"))
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeAPIError(w, newAnalysisError(http.StatusInternalServerError, ErrCodeInternal, "Streaming not supported"))
+			return
+		}
+
+		// === STEP 2: Validate before opening the stream, so bad input gets a real 400 ===
+		rawURL := r.URL.Query().Get("url")
+		if err := ValidateURL(rawURL); err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		refresh := r.URL.Query().Get("refresh") != ""
+
+		// === STEP 3: Open the stream ===
+		ew := &eventWriter{w: w, flusher: flusher, jsonl: wantsJSONLines(r)}
+		if ew.jsonl {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+		} else {
+			w.Header().Set("Content-Type", "text/event-stream")
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no") // stop nginx from buffering the stream
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		log.WithFields(logrus.Fields{
+			"url":    rawURL,
+			"stream": true,
+		}).Info("Starting analysis")
+
+		// === STEP 4: Run the pipeline, forwarding its hooks as events ===
+		hooks := Hooks{
+			OnParsed: func(doc AnalysisResult, linkCount int) {
+				ew.send(EventParsed, newParsedEvent(rawURL, doc, linkCount))
+			},
+			OnLinkChecked: func(link LinkReport, done, total int) {
+				ew.send(EventLinkChecked, linkCheckedEvent{Link: link, Done: done, Total: total})
+			},
+		}
+		result, cache, err := AnalyzeURLCached(r.Context(), rawURL, refresh, Options{Hooks: hooks})
+		if err != nil {
+			ew.send(EventError, asAnalysisError(err))
+			return
+		}
+
+		// Cache hits skip the pipeline (no hooks fired) → replay the same
+		// events from the stored result so clients have a single code path
+		if cache.Hit {
+			replayEvents(ew, rawURL, result)
+		}
+
+		// === STEP 5: Final event with the complete result ===
+		ew.send(EventDone, doneEvent{URL: rawURL, Cache: cache, Result: result})
+	}
+}
+
+// newParsedEvent picks the document-level fields out of a (partial) result
+func newParsedEvent(rawURL string, doc AnalysisResult, linkCount int) parsedEvent {
+	return parsedEvent{
+		URL:          rawURL,
+		HTMLVersion:  doc.HTMLVersion,
+		Doctype:      doc.Doctype,
+		Title:        doc.Title,
+		Headings:     doc.Headings,
+		HasLoginForm: doc.HasLoginForm,
+		Forms:        doc.Forms,
+		LinkCount:    linkCount,
+	}
+}
+
+// replayEvents emits parsed + link_checked events for a finished (cached) result
+func replayEvents(ew *eventWriter, rawURL string, result *AnalysisResult) {
+	total := len(result.Links.Items)
+	ew.send(EventParsed, newParsedEvent(rawURL, *result, total))
+	for i, link := range result.Links.Items {
+		ew.send(EventLinkChecked, linkCheckedEvent{Link: link, Done: i + 1, Total: total})
+	}
+}
@@ -0,0 +1,153 @@
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// sseEventNames pulls the "event: xxx" lines out of an SSE body, in order
+func sseEventNames(body string) []string {
+	var names []string
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for sc.Scan() {
+		if name, ok := strings.CutPrefix(sc.Text(), "event: "); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func TestStreamAnalyzeHandler(t *testing.T) {
+	// Save original, restore after – links are "checked" by a mock
+	oldClient := httpClient
+	defer func() { httpClient = oldClient }()
+	httpClient = &http.Client{
+		Transport: mockTransport(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("")),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`<title>Live</title><h1>x</h1><a href="/a">a</a><a href="/b">b</a>`))
+	}))
+	defer ts.Close()
+
+	h := StreamAnalyzeHandler(logrus.New())
+	stream := func(target, extra string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/analyze/stream?refresh=1&url="+url.QueryEscape(target)+extra, nil)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("SSE emits parsed, one link_checked per link, then done", func(t *testing.T) {
+		rr := stream(ts.URL, "")
+
+		if ct := rr.Header().Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("Content-Type = %q; want text/event-stream", ct)
+		}
+		got := strings.Join(sseEventNames(rr.Body.String()), ",")
+		want := "parsed,link_checked,link_checked,done"
+		if got != want {
+			t.Fatalf("events = %s; want %s\nbody: %s", got, want, rr.Body.String())
+		}
+		if !strings.Contains(rr.Body.String(), `"title":"Live"`) {
+			t.Errorf("parsed event should carry the title; body: %s", rr.Body.String())
+		}
+	})
+
+	t.Run("JSON lines variant", func(t *testing.T) {
+		rr := stream(ts.URL, "&format=jsonl")
+
+		if ct := rr.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+			t.Fatalf("Content-Type = %q; want application/x-ndjson", ct)
+		}
+		lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+		if len(lines) != 4 {
+			t.Fatalf("got %d lines; want 4\nbody: %s", len(lines), rr.Body.String())
+		}
+		var last struct {
+			Event string             `json:"event"`
+			Data  apiAnalyzeResponse `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(lines[3]), &last); err != nil {
+			t.Fatal(err)
+		}
+		if last.Event != EventDone || last.Data.Result == nil || last.Data.Result.Links.OK != 2 {
+			t.Errorf("last line = %+v; want done with 2 ok links", last)
+		}
+	})
+
+	t.Run("pipeline failure is an error event", func(t *testing.T) {
+		rr := stream(ts.URL+"/missing", "")
+
+		got := strings.Join(sseEventNames(rr.Body.String()), ",")
+		if got != EventError || !strings.Contains(rr.Body.String(), ErrCodeUpstreamStatus) {
+			t.Fatalf("events = %s; want a single error event\nbody: %s", got, rr.Body.String())
+		}
+	})
+
+	t.Run("invalid URL is a plain 400 before the stream opens", func(t *testing.T) {
+		rr := stream("not-a-url", "")
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d; want 400", rr.Code)
+		}
+	})
+}
+
+func TestStreamAnalyzeHandler_StopsWhenClientLeaves(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Space out the link checks so the cancellation lands between them
+	oldDelay := HostDelay
+	HostDelay = 50 * time.Millisecond
+	defer func() { HostDelay = oldDelay }()
+
+	// The client "disconnects" as soon as the first link is requested
+	var requests atomic.Int32
+	links := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			cancel()
+		}
+	}))
+	defer links.Close()
+
+	const linkCount = 50
+	var page strings.Builder
+	for i := range linkCount {
+		fmt.Fprintf(&page, `<a href="%s/%d">%d</a>`, links.URL, i, i)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(page.String()))
+	}))
+	defer ts.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/analyze/stream?refresh=1&url="+url.QueryEscape(ts.URL), nil)
+	StreamAnalyzeHandler(logrus.New()).ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+
+	time.Sleep(100 * time.Millisecond) // Anything still running would show up here
+	if sent := requests.Load(); sent != 1 {
+		t.Errorf("%d of %d links requested; want 1 (none after the client left)", sent, linkCount)
+	}
+}
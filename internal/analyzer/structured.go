@@ -0,0 +1,368 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Structured data formats
+const (
+	FormatJSONLD    = "json-ld"
+	FormatMicrodata = "microdata"
+	FormatRDFa      = "rdfa"
+)
+
+// StructuredData is every schema.org item found on the page, plus the
+// JSON-LD blocks we couldn't parse
+type StructuredData struct {
+	Items  []StructuredItem      `json:"items"`
+	Errors []StructuredDataError `json:"errors"`
+}
+
+// StructuredItem is one top-level item (nested items stay inside Properties)
+type StructuredItem struct {
+	Format     string         `json:"format"`            // json-ld / microdata / rdfa
+	Types      []string       `json:"types"`             // schema.org types without the URL, e.g. ["Product"]
+	Properties map[string]any `json:"properties"`        // As found; repeated properties become lists
+	Missing    []string       `json:"missing,omitempty"` // Required properties that aren't there
+}
+
+// StructuredDataError is a JSON-LD block that isn't valid JSON.
+// Block counts the page's JSON-LD scripts from 1; Line/Column are inside that block.
+type StructuredDataError struct {
+	Format  string `json:"format"`
+	Block   int    `json:"block"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+	Snippet string `json:"snippet,omitempty"` // The text around the error
+}
+
+// Location is "block 2, line 3:14" (used by the results template)
+func (e StructuredDataError) Location() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("block %d", e.Block)
+	}
+	return fmt.Sprintf("block %d, line %d:%d", e.Block, e.Line, e.Column)
+}
+
+// requiredProperties per schema.org type, roughly what search engines need
+// for a rich result. Each entry lists alternatives: any one of them will do.
+var requiredProperties = map[string][][]string{
+	"Product":        {{"name"}, {"offers", "review", "aggregateRating"}},
+	"Article":        {{"headline"}, {"author"}, {"datePublished"}, {"image"}},
+	"NewsArticle":    {{"headline"}, {"author"}, {"datePublished"}, {"image"}},
+	"BlogPosting":    {{"headline"}, {"author"}, {"datePublished"}, {"image"}},
+	"BreadcrumbList": {{"itemListElement"}},
+	"Organization":   {{"name"}, {"url"}},
+}
+
+// extractStructuredData walks the whole document once and collects
+// JSON-LD scripts, microdata (itemscope) and RDFa (typeof) items
+func extractStructuredData(doc *html.Node) StructuredData {
+	sd := StructuredData{Items: []StructuredItem{}, Errors: []StructuredDataError{}}
+	blocks := 0
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch {
+			case n.Data == "script" && isJSONLDScript(n):
+				blocks++
+				sd.addJSONLD(textOf(n), blocks)
+
+			case hasAttr(n, "itemscope") && !hasAttr(n, "itemprop"):
+				// Top-level microdata item (one with itemprop belongs to its parent)
+				sd.addItem(FormatMicrodata, schemaTypes(attrValue(n, "itemtype")), microdataProperties(n))
+
+			case attrValue(n, "typeof") != "" && !hasAttr(n, "property"):
+				// Top-level RDFa item
+				sd.addItem(FormatRDFa, schemaTypes(attrValue(n, "typeof")), rdfaProperties(n))
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return sd
+}
+
+// addItem stores one item and checks its required properties
+func (sd *StructuredData) addItem(format string, types []string, props map[string]any) {
+	sd.Items = append(sd.Items, StructuredItem{
+		Format:     format,
+		Types:      types,
+		Properties: props,
+		Missing:    missingProperties(types, props),
+	})
+}
+
+// addJSONLD parses one <script type="application/ld+json"> block.
+// A block may hold one object, an array of objects, or an {"@graph": [...]}.
+func (sd *StructuredData) addJSONLD(text string, block int) {
+	if strings.TrimSpace(text) == "" {
+		sd.Errors = append(sd.Errors, StructuredDataError{Format: FormatJSONLD, Block: block, Message: "empty JSON-LD block"})
+		return
+	}
+
+	var data any
+	if err := json.Unmarshal([]byte(text), &data); err != nil {
+		sd.Errors = append(sd.Errors, jsonLDError(text, block, err))
+		return
+	}
+	for _, node := range jsonLDNodes(data) {
+		sd.addItem(FormatJSONLD, jsonLDTypes(node["@type"]), node)
+	}
+}
+
+// jsonLDError turns a JSON error into a located StructuredDataError
+func jsonLDError(text string, block int, err error) StructuredDataError {
+	e := StructuredDataError{Format: FormatJSONLD, Block: block, Message: err.Error()}
+
+	var offset int64 = -1
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	}
+	if offset < 0 || offset > int64(len(text)) {
+		return e
+	}
+
+	// Offset counts the bytes read *including* the bad one → step back onto it,
+	// then turn the position into a 1-based line and column
+	pos := max(int(offset)-1, 0)
+	before := text[:pos]
+	e.Line = strings.Count(before, "\n") + 1
+	e.Column = pos - strings.LastIndex(before, "\n")
+
+	start, end := max(pos-20, 0), min(pos+20, len(text))
+	e.Snippet = strings.Join(strings.Fields(text[start:end]), " ")
+	return e
+}
+
+// jsonLDNodes returns every object with an @type, looking inside arrays and @graph
+func jsonLDNodes(v any) []map[string]any {
+	var nodes []map[string]any
+	switch t := v.(type) {
+	case []any:
+		for _, item := range t {
+			nodes = append(nodes, jsonLDNodes(item)...)
+		}
+	case map[string]any:
+		if _, ok := t["@type"]; ok {
+			nodes = append(nodes, t)
+		}
+		if graph, ok := t["@graph"]; ok {
+			nodes = append(nodes, jsonLDNodes(graph)...)
+		}
+	}
+	return nodes
+}
+
+// jsonLDTypes reads "@type": "Product" or "@type": ["Product", "Thing"]
+func jsonLDTypes(v any) []string {
+	switch t := v.(type) {
+	case string:
+		return schemaTypes(t)
+	case []any:
+		var types []string
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, schemaTypes(s)...)
+			}
+		}
+		return types
+	}
+	return []string{}
+}
+
+// schemaTypes splits a space-separated type list and strips the vocabulary:
+// "https://schema.org/Product" / "schema:Product" → "Product"
+func schemaTypes(s string) []string {
+	types := []string{}
+	for _, t := range strings.Fields(s) {
+		types = append(types, schemaName(t))
+	}
+	return types
+}
+
+// schemaName strips a vocabulary URL or "schema:" prefix from a type or property
+func schemaName(s string) string {
+	if i := strings.LastIndexAny(s, "/#"); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.TrimPrefix(s, "schema:")
+}
+
+// microdataProperties collects the itemprop values of one itemscope element.
+// Nested itemscope elements become nested maps; their own itemprops stay inside them.
+func microdataProperties(scope *html.Node) map[string]any {
+	props := make(map[string][]any)
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			names := strings.Fields(attrValue(c, "itemprop"))
+			if hasAttr(c, "itemscope") {
+				nested := microdataProperties(c)
+				if types := schemaTypes(attrValue(c, "itemtype")); len(types) > 0 {
+					nested["@type"] = types[0]
+				}
+				for _, name := range names {
+					props[name] = append(props[name], nested)
+				}
+				continue // its children belong to the nested item
+			}
+			for _, name := range names {
+				props[name] = append(props[name], microdataValue(c))
+			}
+			walk(c)
+		}
+	}
+	walk(scope)
+	return flattenProperties(props)
+}
+
+// microdataValue is the value of an itemprop element, which depends on the tag
+// (<meta content>, <a href>, <img src>, <time datetime>, or else the text)
+func microdataValue(n *html.Node) string {
+	switch n.Data {
+	case "meta":
+		return attrValue(n, "content")
+	case "a", "area", "link":
+		return attrValue(n, "href")
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		return attrValue(n, "src")
+	case "object":
+		return attrValue(n, "data")
+	case "data", "meter":
+		return attrValue(n, "value")
+	case "time":
+		if dt := attrValue(n, "datetime"); dt != "" {
+			return dt
+		}
+	}
+	return textContent(n)
+}
+
+// rdfaProperties collects the property values of one typeof element,
+// the RDFa equivalent of microdataProperties
+func rdfaProperties(scope *html.Node) map[string]any {
+	props := make(map[string][]any)
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			var names []string
+			for _, p := range strings.Fields(attrValue(c, "property")) {
+				names = append(names, schemaName(p))
+			}
+			if attrValue(c, "typeof") != "" {
+				nested := rdfaProperties(c)
+				if types := schemaTypes(attrValue(c, "typeof")); len(types) > 0 {
+					nested["@type"] = types[0]
+				}
+				for _, name := range names {
+					props[name] = append(props[name], nested)
+				}
+				continue
+			}
+			for _, name := range names {
+				props[name] = append(props[name], rdfaValue(c))
+			}
+			walk(c)
+		}
+	}
+	walk(scope)
+	return flattenProperties(props)
+}
+
+// rdfaValue: content= wins, then a link target, then the text
+func rdfaValue(n *html.Node) string {
+	for _, key := range []string{"content", "resource", "href", "src"} {
+		if v := attrValue(n, key); v != "" {
+			return v
+		}
+	}
+	return textContent(n)
+}
+
+// flattenProperties turns single values into plain values and keeps lists for repeats,
+// so microdata/RDFa look like JSON-LD in the output
+func flattenProperties(props map[string][]any) map[string]any {
+	out := make(map[string]any, len(props))
+	for name, values := range props {
+		if len(values) == 1 {
+			out[name] = values[0]
+		} else {
+			out[name] = values
+		}
+	}
+	return out
+}
+
+// missingProperties lists the required properties of types that props lacks.
+// Alternatives are reported together, e.g. "offers or review or aggregateRating".
+func missingProperties(types []string, props map[string]any) []string {
+	var missing []string
+	for _, t := range types {
+		for _, alternatives := range requiredProperties[t] {
+			found := false
+			for _, name := range alternatives {
+				if hasProperty(props, name) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				missing = append(missing, strings.Join(alternatives, " or "))
+			}
+		}
+	}
+	return missing
+}
+
+// hasProperty is true when name is set to something non-empty
+func hasProperty(props map[string]any, name string) bool {
+	switch v := props[name].(type) {
+	case nil:
+		return false
+	case string:
+		return strings.TrimSpace(v) != ""
+	case []any:
+		return len(v) > 0
+	}
+	return true
+}
+
+// isJSONLDScript matches <script type="application/ld+json"> (any case, optional parameters)
+func isJSONLDScript(n *html.Node) bool {
+	t, _, _ := strings.Cut(attrValue(n, "type"), ";")
+	return strings.EqualFold(strings.TrimSpace(t), "application/ld+json")
+}
+
+// textOf returns the raw text of n's children (script contents are one text node)
+func textOf(n *html.Node) string {
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			sb.WriteString(c.Data)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzePage_StructuredData(t *testing.T) {
+	page := `<html><head>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@type": "Product", "name": "Widget",
+ "offers": {"@type": "Offer", "price": "9.99"}}
+</script>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@graph": [
+  {"@type": "Organization", "name": "ACME"},
+  {"@type": ["NewsArticle"], "headline": "Hi", "author": "Ann", "datePublished": "2024-01-01", "image": []}
+]}
+</script>
+<script type="application/ld+json">
+{
+  "@type": "Product",
+  "name": "Broken" "oops"
+}
+</script>
+</head><body>
+<div itemscope itemtype="https://schema.org/BreadcrumbList">
+  <span itemprop="itemListElement" itemscope itemtype="https://schema.org/ListItem">
+    <a itemprop="item" href="/shop"><span itemprop="name">Shop</span></a>
+    <meta itemprop="position" content="1">
+  </span>
+</div>
+<div vocab="https://schema.org/" typeof="Product">
+  <span property="name">Gadget</span>
+  <div property="offers" typeof="Offer"><span property="price" content="5"></span></div>
+</div>
+</body></html>`
+
+	result, err := AnalyzePage(strings.NewReader(page), "")
+	if err != nil {
+		t.Fatalf("AnalyzePage: %v", err)
+	}
+	sd := result.StructuredData
+
+	// === Items: format, types and missing required properties ===
+	type summary struct {
+		format  string
+		types   []string
+		missing []string
+	}
+	var got []summary
+	for _, item := range sd.Items {
+		got = append(got, summary{item.Format, item.Types, item.Missing})
+	}
+	want := []summary{
+		{FormatJSONLD, []string{"Product"}, nil},
+		{FormatJSONLD, []string{"Organization"}, []string{"url"}},
+		{FormatJSONLD, []string{"NewsArticle"}, []string{"image"}}, // empty list doesn't count
+		{FormatMicrodata, []string{"BreadcrumbList"}, nil},
+		{FormatRDFa, []string{"Product"}, nil},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("items = %+v; want %+v", got, want)
+	}
+
+	// === Nested microdata / RDFa items keep their own properties ===
+	crumb, _ := sd.Items[3].Properties["itemListElement"].(map[string]any)
+	wantCrumb := map[string]any{"@type": "ListItem", "item": "/shop", "name": "Shop", "position": "1"}
+	if !reflect.DeepEqual(crumb, wantCrumb) {
+		t.Errorf("breadcrumb item = %v; want %v", crumb, wantCrumb)
+	}
+	offer, _ := sd.Items[4].Properties["offers"].(map[string]any)
+	if offer["@type"] != "Offer" || offer["price"] != "5" || sd.Items[4].Properties["name"] != "Gadget" {
+		t.Errorf("RDFa properties = %v", sd.Items[4].Properties)
+	}
+
+	// === The broken block is reported with its location ===
+	if len(sd.Errors) != 1 {
+		t.Fatalf("errors = %+v; want 1", sd.Errors)
+	}
+	e := sd.Errors[0]
+	if e.Block != 3 || e.Line != 4 || e.Location() != "block 3, line 4:20" || !strings.Contains(e.Snippet, `"oops"`) {
+		t.Errorf("error = %+v (%s); want block 3, line 4:20 near \"oops\"", e, e.Location())
+	}
+}
+
+func TestAnalyzePage_StructuredData_Empty(t *testing.T) {
+	result, err := AnalyzePage(strings.NewReader(`<script type="application/ld+json">  </script><p>hi</p>`), "")
+	if err != nil {
+		t.Fatalf("AnalyzePage: %v", err)
+	}
+	sd := result.StructuredData
+	if len(sd.Items) != 0 || len(sd.Errors) != 1 || sd.Errors[0].Message != "empty JSON-LD block" {
+		t.Errorf("structured data = %+v; want no items and one empty-block error", sd)
+	}
+}
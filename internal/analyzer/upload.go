@@ -0,0 +1,196 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MaxHTMLUploadBytes caps uploaded/pasted HTML (5 MB)
+const MaxHTMLUploadBytes = 5 << 20
+
+// htmlInput is HTML handed to us directly instead of fetched from a URL
+type htmlInput struct {
+	HTML       []byte // The markup itself
+	BaseURL    string // Optional: resolves relative links ("" = leave them unresolved)
+	CheckLinks bool   // Optional: actually request the links (off by default)
+}
+
+// apiHTMLRequest is the JSON form of POST /api/v1/analyze/html
+type apiHTMLRequest struct {
+	HTML       string `json:"html"`
+	BaseURL    string `json:"base_url"`
+	CheckLinks bool   `json:"check_links"`
+}
+
+// AnalyzeHTML analyzes markup that was uploaded or pasted.
+// Same as AnalyzePage, plus:
+// - baseURL is validated when given (it decides internal vs external)
+// - link checking only happens when checkLinks is true, and stops when ctx is done
+// - without baseURL, only absolute links can be checked
+func AnalyzeHTML(ctx context.Context, body io.Reader, baseURL string, checkLinks bool) (*AnalysisResult, error) {
+	if baseURL != "" {
+		if err := ValidateURL(baseURL); err != nil {
+			return nil, newAnalysisError(http.StatusBadRequest, ErrCodeInvalidURL, "Invalid base URL format")
+		}
+	}
+
+	result, err := AnalyzePageWithOptions(body, baseURL, Options{SkipLinkCheck: !checkLinks, Context: ctx})
+	if err != nil {
+		return nil, newAnalysisError(http.StatusUnprocessableEntity, ErrCodeParseFailed, "HTML parsing error: %v", err)
+	}
+	return result, nil
+}
+
+// readHTMLInput extracts the HTML and options from any of the accepted formats:
+// - multipart/form-data: "file" upload or "html" text field, plus "base_url", "check_links"
+// - application/x-www-form-urlencoded: "html", "base_url", "check_links"
+// - application/json: {"html": "...", "base_url": "...", "check_links": true}
+// - anything else (e.g. text/html): the raw body is the HTML; options come from the query string
+func readHTMLInput(w http.ResponseWriter, r *http.Request) (htmlInput, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxHTMLUploadBytes)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var in htmlInput
+	switch mediaType {
+	case "multipart/form-data", "application/x-www-form-urlencoded":
+		if err := r.ParseMultipartForm(MaxHTMLUploadBytes); err != nil && err != http.ErrNotMultipart {
+			return in, newAnalysisError(http.StatusBadRequest, ErrCodeBadRequest, "Could not read upload: %v", err)
+		}
+		in.BaseURL = strings.TrimSpace(r.FormValue("base_url"))
+		in.CheckLinks = isTruthy(r.FormValue("check_links"))
+
+		// An uploaded file wins over the pasted text
+		if file, _, err := r.FormFile("file"); err == nil {
+			defer file.Close()
+			data, err := io.ReadAll(file)
+			if err != nil {
+				return in, newAnalysisError(http.StatusBadRequest, ErrCodeBadRequest, "Could not read upload: %v", err)
+			}
+			in.HTML = data
+		} else {
+			in.HTML = []byte(r.FormValue("html"))
+		}
+
+	case "application/json":
+		var req apiHTMLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return in, newAnalysisError(http.StatusBadRequest, ErrCodeBadRequest, "Invalid JSON body: %v", err)
+		}
+		in = htmlInput{HTML: []byte(req.HTML), BaseURL: strings.TrimSpace(req.BaseURL), CheckLinks: req.CheckLinks}
+
+	default:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return in, newAnalysisError(http.StatusRequestEntityTooLarge, ErrCodeBadRequest, "Could not read body: %v", err)
+		}
+		in.HTML = data
+		in.BaseURL = strings.TrimSpace(r.URL.Query().Get("base_url"))
+		in.CheckLinks = isTruthy(r.URL.Query().Get("check_links"))
+	}
+
+	if len(bytes.TrimSpace(in.HTML)) == 0 {
+		return in, newAnalysisError(http.StatusBadRequest, ErrCodeMissingHTML, "HTML is required (upload a file or paste markup)")
+	}
+	return in, nil
+}
+
+// isTruthy accepts the usual ways forms and query strings say "yes"
+func isTruthy(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "1", "true", "on", "yes":
+		return true
+	}
+	return false
+}
+
+// AnalyzeHTMLHandler serves POST /analyze/html – the HTML-form version:
+// renders results.html just like AnalyzeHandler.
+func AnalyzeHTMLHandler(log *logrus.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		in, err := readHTMLInput(w, r)
+		if err != nil {
+			renderError(w, err.Error())
+			return
+		}
+
+		log.WithFields(logrus.Fields{
+			"base_url":    in.BaseURL,
+			"bytes":       len(in.HTML),
+			"check_links": in.CheckLinks,
+		}).Info("Starting analysis of uploaded HTML")
+
+		result, err := AnalyzeHTML(r.Context(), bytes.NewReader(in.HTML), in.BaseURL, in.CheckLinks)
+		if err != nil {
+			renderError(w, err.Error())
+			return
+		}
+
+		data := pageData{
+			URL:            in.BaseURL, // may be empty: template then says "uploaded HTML"
+			Uploaded:       true,
+			HTMLVersion:    result.HTMLVersion,
+			Doctype:        result.Doctype,
+			Title:          result.Title,
+			Headings:       result.Headings,
+			Links:          result.Links,
+			HasLoginForm:   result.HasLoginForm,
+			Forms:          result.Forms,
+			SEO:            result.SEO,
+			StructuredData: result.StructuredData,
+			Accessibility:  result.Accessibility,
+			MixedContent:   result.MixedContent,
+		}
+		if err := Tmpl.Execute(w, data); err != nil {
+			log.WithError(err).Error("Template render failed")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+	}
+}
+
+// APIAnalyzeHTMLHandler serves POST /api/v1/analyze/html – JSON in/out
+// (or raw text/html in, see readHTMLInput), same response shape as /api/v1/analyze.
+func APIAnalyzeHTMLHandler(log *logrus.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeAPIError(w, newAnalysisError(http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "Method not allowed"))
+			return
+		}
+
+		in, err := readHTMLInput(w, r)
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+
+		log.WithFields(logrus.Fields{
+			"base_url":    in.BaseURL,
+			"bytes":       len(in.HTML),
+			"check_links": in.CheckLinks,
+			"api":         "v1",
+		}).Info("Starting analysis of uploaded HTML")
+
+		result, err := AnalyzeHTML(r.Context(), bytes.NewReader(in.HTML), in.BaseURL, in.CheckLinks)
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, apiAnalyzeResponse{
+			URL:    in.BaseURL,
+			Result: result,
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+const uploadPage = `<!DOCTYPE html><html><head><title>Staging</title></head><body>
+<h1>Hi</h1><a href="/about">About</a><a href="https://other.example/">Other</a></body></html>`
+
+// doHTMLUpload sends a request to the HTML API handler and decodes the answer into out
+func doHTMLUpload(t *testing.T, target, contentType string, body []byte, out any) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rr := httptest.NewRecorder()
+	APIAnalyzeHTMLHandler(logrus.New()).ServeHTTP(rr, req)
+	if err := json.Unmarshal(rr.Body.Bytes(), out); err != nil {
+		t.Fatalf("response is not JSON: %v; body: %s", err, rr.Body.String())
+	}
+	return rr
+}
+
+func TestAPIAnalyzeHTMLHandler_Formats(t *testing.T) {
+	pageJSON, _ := json.Marshal(uploadPage)
+
+	// Multipart body with a file upload + form fields
+	var mp bytes.Buffer
+	mw := multipart.NewWriter(&mp)
+	fw, _ := mw.CreateFormFile("file", "index.html")
+	fw.Write([]byte(uploadPage))
+	mw.WriteField("base_url", "https://staging.example.com")
+	mw.Close()
+
+	cases := []struct {
+		name        string
+		target      string
+		contentType string
+		body        []byte
+	}{
+		{"raw text/html", "/api/v1/analyze/html?base_url=https://staging.example.com", "text/html", []byte(uploadPage)},
+		{"json", "/api/v1/analyze/html", "application/json",
+			[]byte(`{"html":` + string(pageJSON) + `,"base_url":"https://staging.example.com"}`)},
+		{"multipart file", "/api/v1/analyze/html", mw.FormDataContentType(), mp.Bytes()},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var resp apiAnalyzeResponse
+			rr := doHTMLUpload(t, tc.target, tc.contentType, tc.body, &resp)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("status = %d; want 200; body: %s", rr.Code, rr.Body.String())
+			}
+			res := resp.Result
+			if res == nil || res.Title != "Staging" || res.Headings["h1"] != 1 {
+				t.Fatalf("result = %+v; want title Staging and one h1", res)
+			}
+			if res.Links.Internal != 1 || res.Links.External != 1 {
+				t.Errorf("internal/external = %d/%d; want 1/1", res.Links.Internal, res.Links.External)
+			}
+			// check_links is off by default → nothing was requested
+			if res.Links.Unchecked != 2 || res.Links.OK+res.Links.Broken != 0 {
+				t.Errorf("links = %+v; want all unchecked", res.Links)
+			}
+		})
+	}
+}
+
+func TestAPIAnalyzeHTMLHandler_Errors(t *testing.T) {
+	cases := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		wantStatus  int
+		wantCode    string
+	}{
+		{"empty body", "/api/v1/analyze/html", "text/html", "  \n", http.StatusBadRequest, ErrCodeMissingHTML},
+		{"empty JSON html", "/api/v1/analyze/html", "application/json", `{"html":""}`, http.StatusBadRequest, ErrCodeMissingHTML},
+		{"bad base URL", "/api/v1/analyze/html?base_url=nope", "text/html", uploadPage, http.StatusBadRequest, ErrCodeInvalidURL},
+		{"malformed JSON", "/api/v1/analyze/html", "application/json", "{oops", http.StatusBadRequest, ErrCodeBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var resp apiErrorResponse
+			rr := doHTMLUpload(t, tc.target, tc.contentType, []byte(tc.body), &resp)
+
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d; want %d", rr.Code, tc.wantStatus)
+			}
+			if resp.Error == nil || resp.Error.Code != tc.wantCode {
+				t.Fatalf("error = %+v; want code %q", resp.Error, tc.wantCode)
+			}
+		})
+	}
+}
+
+func TestAnalyzeHTML_CheckLinks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	page := `<a href="/ok">ok</a><a href="/missing">gone</a>`
+	res, err := AnalyzeHTML(context.Background(), strings.NewReader(page), srv.URL, true)
+	if err != nil {
+		t.Fatalf("AnalyzeHTML: %v", err)
+	}
+	if res.Links.OK != 1 || res.Links.Broken != 1 {
+		t.Errorf("ok/broken = %d/%d; want 1/1", res.Links.OK, res.Links.Broken)
+	}
+}
+
+func TestAnalyzeHTML_NoBaseURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	// Absolute links are still checked; relative ones have nothing to resolve against
+	page := `<a href="` + srv.URL + `/x">abs</a><a href="/about">rel</a>`
+	res, err := AnalyzeHTML(context.Background(), strings.NewReader(page), "", true)
+	if err != nil {
+		t.Fatalf("AnalyzeHTML: %v", err)
+	}
+	abs, rel := res.Links.Items[0], res.Links.Items[1]
+	if abs.Health != LinkOK || abs.URL != srv.URL+"/x" || abs.Kind != LinkUnclassified {
+		t.Errorf("absolute link = %+v; want checked ok, unclassified (no page host)", abs)
+	}
+	if rel.Health != LinkUnchecked || rel.URL != "" || rel.Error == "" {
+		t.Errorf("relative link = %+v; want unchecked with an error", rel)
+	}
+
+	// A cancelled request checks nothing
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err = AnalyzeHTML(ctx, strings.NewReader(page), "", true)
+	if err != nil {
+		t.Fatalf("AnalyzeHTML: %v", err)
+	}
+	if got := res.Links.Items[0]; got.Health != LinkUnchecked || got.Error != linkCancelledMessage {
+		t.Errorf("after cancel: %+v; want unchecked, %q", got, linkCancelledMessage)
+	}
+}
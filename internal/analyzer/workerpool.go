@@ -4,7 +4,7 @@ import (
 	"sync"
 )
 
-// MaxWorkers = max number of simultaneous link checks (goroutines)
+// MaxWorkers = max number of simultaneous requests (page fetches + link checks)
 // Too high → overload server, use too much RAM/CPU
 // Too low  → slow analysis
 // 100 is a good default for most servers
@@ -15,9 +15,50 @@ const MaxWorkers = 100
 // Prevents memory explosion on huge pages
 const MaxQueue = 1000
 
-// workerPool is a channel that acts like a "token bucket"
+// WorkerPool is a channel that acts like a "token bucket":
+// each running task holds one token, so at most `size` tasks run at once.
+// Every outgoing request (page fetch or link check) holds a token of the
+// shared pool below while it runs. Batches, crawls and sitemaps also get
+// their own pool of pages in flight; a page never holds a shared token while
+// its link checks wait for one, so the two levels can't deadlock.
+type WorkerPool struct {
+	tokens chan struct{}
+}
+
+// NewWorkerPool creates a pool that lets `size` tasks run at the same time
+func NewWorkerPool(size int) *WorkerPool {
+	if size < 1 {
+		size = 1 // a pool with no tokens would block forever
+	}
+
+	// Create a buffered channel with `size` slots
+	// Each slot holds an empty struct{} (uses zero memory)
+	p := &WorkerPool{tokens: make(chan struct{}, size)}
+
+	// Pre-fill the pool with `size` "tokens"
+	// This means: "Yes, you can start `size` workers right now"
+	for i := 0; i < size; i++ {
+		p.tokens <- struct{}{} // Put a token in
+	}
+	return p
+}
+
+// Acquire gets a token → allows one task to proceed
+// If no tokens left → **blocks** (waits) until someone calls Release()
+func (p *WorkerPool) Acquire() {
+	<-p.tokens
+}
+
+// Release returns a token to the pool
+// Called with `defer pool.Release()` in goroutines
+func (p *WorkerPool) Release() {
+	// Put the token back — now one more worker can run
+	p.tokens <- struct{}{}
+}
+
+// workerPool is the shared pool for page fetches and link checks (MaxWorkers tokens)
 var (
-	workerPool chan struct{}
+	workerPool *WorkerPool
 	once       sync.Once
 )
 
@@ -26,19 +67,11 @@ var (
 func initWorkerPool() {
 	// sync.Once ensures this block runs **only once**, no matter how many goroutines call it
 	once.Do(func() {
-		// Create a buffered channel with MaxWorkers slots
-		// Each slot holds an empty struct{} (uses zero memory)
-		workerPool = make(chan struct{}, MaxWorkers)
-
-		// Pre-fill the pool with MaxWorkers "tokens"
-		// This means: "Yes, you can start 100 workers right now"
-		for i := 0; i < MaxWorkers; i++ {
-			workerPool <- struct{}{} // Put a token in
-		}
+		workerPool = NewWorkerPool(MaxWorkers)
 	})
 }
 
-// Acquire gets a token → allows one link check to proceed
+// Acquire gets a token from the shared request pool
 // If no tokens left → **blocks** (waits) until someone calls Release()
 func Acquire() {
 	// Make sure pool is ready (first call initializes it)
@@ -46,13 +79,12 @@ func Acquire() {
 
 	// Wait here until a token is available
 	// This blocks the goroutine — prevents too many workers
-	<-workerPool
+	workerPool.Acquire()
 }
 
-// Release returns a token to the pool
+// Release returns a token to the shared request pool
 // Called with `defer Release()` in goroutines
 // Allows another link check to start
 func Release() {
-	// Put the token back — now one more worker can run
-	workerPool <- struct{}{}
-}
\ No newline at end of file
+	workerPool.Release()
+}
@@ -0,0 +1,94 @@
+// Live results page: subscribes to /api/v1/analyze/stream (Server-Sent Events)
+// and fills the page in as events arrive:
+//   parsed       → document info + headings
+//   link_checked → one table row + counters
+//   done         → final status line
+//   error        → error box
+document.addEventListener("DOMContentLoaded", function () {
+    var params = new URLSearchParams(window.location.search);
+    var url = params.get("url") || "";
+    var $ = function (id) { return document.getElementById(id); };
+
+    var pageLink = $("page-url");
+    pageLink.textContent = url;
+    pageLink.href = url;
+
+    var counts = {};
+    function bump(name) {
+        counts[name] = (counts[name] || 0) + 1;
+        var el = document.querySelector('[data-count="' + name + '"]');
+        if (el) { el.textContent = counts[name]; }
+    }
+
+    function cell(row, text) {
+        var td = row.insertCell();
+        td.textContent = text;
+        return td;
+    }
+
+    function showError(msg) {
+        $("error").textContent = msg;
+        $("error").hidden = false;
+        $("status").textContent = "";
+    }
+
+    var query = "url=" + encodeURIComponent(url);
+    if (params.get("refresh")) { query += "&refresh=1"; }
+    var source = new EventSource("/api/v1/analyze/stream?" + query);
+
+    source.addEventListener("parsed", function (e) {
+        var doc = JSON.parse(e.data);
+        $("html-version").textContent = doc.html_version;
+        $("title").textContent = doc.title;
+        $("login").textContent = doc.has_login_form ? "Yes" : "No";
+        $("status").textContent = "Checking " + doc.link_count + " links…";
+
+        var list = $("headings");
+        list.textContent = "";
+        var levels = Object.keys(doc.headings || {}).sort();
+        levels.forEach(function (level) {
+            var li = document.createElement("li");
+            li.textContent = level + ": " + doc.headings[level];
+            list.appendChild(li);
+        });
+        if (levels.length === 0) {
+            list.innerHTML = "<li>No headings found.</li>";
+        }
+    });
+
+    source.addEventListener("link_checked", function (e) {
+        var ev = JSON.parse(e.data);
+        var link = ev.link;
+        var row = $("links").insertRow();
+        row.className = link.health;
+        cell(row, link.text);
+        cell(row, link.url || link.href).title = link.href;
+        cell(row, link.kind);
+        cell(row, link.health);
+        cell(row, link.status || "");
+        cell(row, link.latency_ms);
+        cell(row, link.error || "");
+
+        bump(link.kind);
+        bump(link.health);
+        $("progress").textContent = "(" + ev.done + " / " + ev.total + ")";
+    });
+
+    source.addEventListener("done", function (e) {
+        var res = JSON.parse(e.data);
+        $("status").textContent = res.cache.hit
+            ? "Done – served from cache (analyzed " + res.cache.age_seconds + "s ago)."
+            : "Done.";
+        source.close();
+    });
+
+    source.addEventListener("error", function (e) {
+        // Our own "error" events carry data; connection errors don't
+        if (e.data) {
+            showError(JSON.parse(e.data).message);
+        } else if (source.readyState !== EventSource.CLOSED) {
+            showError("Connection to the server was lost.");
+        }
+        source.close();
+    });
+});
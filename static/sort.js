@@ -0,0 +1,28 @@
+// Click-to-sort for any <table class="sortable">.
+// Columns marked data-type="number" sort numerically, others alphabetically.
+// Plain progressive enhancement: without JS the table is still readable.
+document.addEventListener("DOMContentLoaded", function () {
+    document.querySelectorAll("table.sortable").forEach(function (table) {
+        var headers = table.querySelectorAll("thead th");
+        headers.forEach(function (th, col) {
+            th.addEventListener("click", function () {
+                var asc = !th.classList.contains("asc");
+                headers.forEach(function (h) { h.classList.remove("asc", "desc"); });
+                th.classList.add(asc ? "asc" : "desc");
+
+                var numeric = th.dataset.type === "number";
+                var tbody = table.tBodies[0];
+                var rows = Array.prototype.slice.call(tbody.rows);
+                rows.sort(function (a, b) {
+                    var x = a.cells[col].textContent.trim();
+                    var y = b.cells[col].textContent.trim();
+                    var cmp = numeric
+                        ? (parseFloat(x) || 0) - (parseFloat(y) || 0)
+                        : x.localeCompare(y);
+                    return asc ? cmp : -cmp;
+                });
+                rows.forEach(function (r) { tbody.appendChild(r); });
+            });
+        });
+    });
+});